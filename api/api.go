@@ -3,7 +3,9 @@ package api
 import (
 	"ret/api/handler"
 	"ret/config"
+	"ret/pkg/filestore"
 	"ret/storage"
+	"ret/worker"
 
 	"github.com/gin-gonic/gin"
 
@@ -13,9 +15,9 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 )
 
-func SetUpApi(r *gin.Engine, cfg *config.Config, strg storage.StorageI) {
+func SetUpApi(r *gin.Engine, cfg *config.Config, strg storage.StorageI, pool *worker.Pool, files *filestore.Store) {
 
-	handler := handler.NewHandler(cfg, strg)
+	handler := handler.NewHandler(cfg, strg, pool, files)
 
 	// City ...
 	r.POST("/city", handler.CreateCity)
@@ -33,16 +35,48 @@ func SetUpApi(r *gin.Engine, cfg *config.Config, strg storage.StorageI) {
 	r.PUT("/country/:id", handler.CountryUpdate)
 	r.DELETE("/country/:id", handler.CountryDelete)
 
-	r.POST("/upload/:table_slug", handler.UploadCountry)
-
 	// Airport
 	r.POST("/airport", handler.CreateAirport)
 	r.GET("/airport/:id", handler.AirportGetById)
 	r.GET("/airport", handler.AirportGetList)
 	r.PUT("/airport/:id", handler.AirportUpdate)
 	r.DELETE("/airport/:id", handler.AirportDelete)
+	r.POST("/airport/:id/image", handler.AirportImageUpload)
+	r.GET("/airport/:id/image", handler.AirportImageGet)
+
+	// Uploads
+	r.GET("/upload", handler.UploadSchemas)
+	r.POST("/upload/archive", handler.UploadArchive)
+	r.POST("/upload/:table_slug", handler.Upload)
+
+	// Exports
+	r.GET("/export/workbook", handler.ExportWorkbook)
+	r.GET("/export/:table_slug", handler.Export)
+
+	// Chunked uploads
+	r.POST("/uploads", handler.CreateUpload)
+	r.GET("/uploads/:id", handler.UploadGetById)
+	r.HEAD("/uploads/:id", handler.UploadGetById)
+	r.PATCH("/uploads/:id", handler.UploadChunk)
+	r.POST("/uploads/:id/finalize", handler.UploadFinalize)
+	r.DELETE("/uploads/:id", handler.UploadDelete)
+
+	// Import jobs
+	r.GET("/import-jobs", handler.ImportJobGetList)
+	r.GET("/import-jobs/:id", handler.ImportJobGetById)
+	r.POST("/import-jobs/:id/cancel", handler.ImportJobCancel)
+	r.GET("/import-jobs/:id/report", handler.ImportJobGetReport)
+	r.POST("/import-jobs/:id/rollback", handler.ImportJobRollback)
+
+	// Mapping profiles
+	r.POST("/mapping-profiles", handler.CreateMappingProfile)
+	r.GET("/mapping-profiles/:id", handler.MappingProfileGetById)
+	r.GET("/mapping-profiles", handler.MappingProfileGetList)
+	r.PUT("/mapping-profiles/:id", handler.MappingProfileUpdate)
+	r.DELETE("/mapping-profiles/:id", handler.MappingProfileDelete)
 
-	r.POST("/upload/airport", handler.UploadAirport)
+	// Search
+	r.GET("/search", handler.Search)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 }
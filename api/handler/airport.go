@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"ret/api/models"
 	"ret/pkg/helpers"
+	"ret/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -68,9 +71,18 @@ func (h *Handler) AirportGetById(c *gin.Context) {
 // @Tags Airport
 // @Accept json
 // @Produce json
-// @Param limit query int false "Limit"
+// @Param limit query int false "Limit, 10 by default and at most 100"
 // @Param offset query int false "Offset"
+// @Param cursor query string false "next_cursor or prev_cursor of another page, replaces offset"
+// @Param country_id query string false "Country ID"
+// @Param city_id query string false "City ID"
+// @Param code query string false "Airport code"
+// @Param product_count_min query int false "Minimum product count"
+// @Param product_count_max query int false "Maximum product count"
+// @Param sort query string false "Sort by title, code, product_count, country, city, created_at or updated_at; comma separated, - for descending"
 // @Success 200 {object} Response{data=models.GetListAirportResponse} "GetListAirportResponseBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
 // @Router /airport [get]
 func (h *Handler) AirportGetList(c *gin.Context) {
 	var airport models.GetListAirportRequest
@@ -80,6 +92,10 @@ func (h *Handler) AirportGetList(c *gin.Context) {
 		return
 	}
 	resp, err := h.strg.Airport().GetList(airport)
+	if errors.Is(err, storage.ErrInvalidListQuery) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		handleResponse(c, 500, "Airport does not exist: "+err.Error())
 		return
@@ -128,7 +144,7 @@ func (h *Handler) AirportUpdate(c *gin.Context) {
 // AirportDelete godoc
 // @Router /airport/{id} [delete]
 // @Summary Delete Airport
-// @Description Delete Airport
+// @Description Delete Airport and its uploaded image
 // @Tags Airport
 // @Accept json
 // @Produce json
@@ -144,57 +160,21 @@ func (h *Handler) AirportDelete(c *gin.Context) {
 		return
 	}
 
-	err := h.strg.Airport().Delete(models.AirportPrimaryKey{Id: id})
-	if err != nil {
+	image, err := h.strg.Airport().GetImage(models.AirportPrimaryKey{Id: id})
+	if err != nil && err != sql.ErrNoRows {
 		handleResponse(c, 500, "airport does not delete: "+err.Error())
 		return
 	}
 
-	handleResponse(c, http.StatusNoContent, nil)
-}
-
-
-
-
-
-
-// UploadAirports godoc
-// @Summary Загрузка аэропортов
-// @Description Загрузка аэропортов из файла
-// @Tags Airport
-// @Accept multipart/form-data
-// @Produce json
-// @Param file formData file true "Файл JSON с аэропортами"
-// @Success 200 {string} string "Файл успешно загружен"
-// @Failure 400 {object} Response{data=string} "Неверный аргумент"
-// @Failure 500 {object} Response{data=string} "Ошибка сервера"
-// @Router /upload/airport [post]
-func (h *Handler) UploadAirport(c *gin.Context) {
-
-	file, err := c.FormFile("file")
-	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Ошибка при получении файла: "+err.Error())
-		return
-	}
-
-	if file.Header.Get("Content-Type") != "application/json" {
-		handleResponse(c, http.StatusBadRequest, "Неверный формат файла. загрузите файл JSON.")
-		return
-	}
-
-	uploadPath := "uploads/"
-	err = c.SaveUploadedFile(file, uploadPath+file.Filename)
+	err = h.strg.Airport().Delete(models.AirportPrimaryKey{Id: id})
 	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, "Ошибка при сохранении файла: "+err.Error())
+		handleResponse(c, 500, "airport does not delete: "+err.Error())
 		return
 	}
 
-	filePath := uploadPath + file.Filename
-	err = h.strg.Airport().ImportFromFileAirport(filePath)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, "Ошибка при импорте данных: "+err.Error())
-		return
+	if image != nil && image.Key != "" {
+		h.deleteAirportImage(c.Request.Context(), *image)
 	}
 
-	handleResponse(c, http.StatusOK, "Файл успешно загружен")
+	handleResponse(c, http.StatusNoContent, nil)
 }
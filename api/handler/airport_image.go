@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"ret/api/models"
+	"ret/config"
+	"ret/pkg/blob"
+	"ret/pkg/helpers"
+	"ret/pkg/imaging"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// airportImageOriginal is the size name of the image as it was uploaded.
+const airportImageOriginal = "original"
+
+// AirportImageUpload godoc
+// @Summary Upload Airport image
+// @Description Upload a JPEG, PNG or GIF image of the airport. Small (160px) and medium (640px) JPEG thumbnails are generated and the image field is set to GET /airport/{id}/image. A previous image is deleted.
+// @Tags Airport
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Airport ID"
+// @Param file formData file true "JPEG, PNG or GIF image"
+// @Success 200 {object} Response{data=models.Airport} "AirportBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 404 {object} Response{data=string} "Airport does not exist"
+// @Failure 413 {object} Response{data=models.UploadError} "Image is too large"
+// @Failure 415 {object} Response{data=models.UploadError} "Not a JPEG, PNG or GIF image"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /airport/{id}/image [post]
+func (h *Handler) AirportImageUpload(c *gin.Context) {
+	id := c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id not valid uuid")
+		return
+	}
+
+	old, ok := h.getAirportImage(c, id)
+	if !ok {
+		return
+	}
+
+	limit := h.cfg.ImageMaxSize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+multipartSlack)
+
+	file, err := c.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		uploadTooLarge(c, limit)
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while getting file: "+err.Error())
+		return
+	}
+	if file.Size > limit {
+		uploadTooLarge(c, limit)
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while getting file: "+err.Error())
+		return
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while getting file: "+err.Error())
+		return
+	}
+
+	img, contentType, err := imaging.Decode(data)
+	if err != nil {
+		unsupportedUpload(c, "Image is not valid: "+err.Error(), contentType)
+		return
+	}
+
+	// The key changes with the content, so a new image never serves a
+	// cached copy of the old one.
+	sum := sha256.Sum256(data)
+	image := models.AirportImage{
+		AirportId:   id,
+		Key:         "airports/" + id + "/" + hex.EncodeToString(sum[:8]),
+		ContentType: contentType,
+	}
+
+	ctx := c.Request.Context()
+	err = h.files.SaveAs(ctx, airportImageKey(image, airportImageOriginal), bytes.NewReader(data))
+	for _, size := range imaging.Sizes {
+		if err != nil {
+			break
+		}
+
+		var thumbnail bytes.Buffer
+		err = imaging.Thumbnail(&thumbnail, img, size.Max)
+		if err == nil {
+			err = h.files.SaveAs(ctx, airportImageKey(image, size.Name), &thumbnail)
+		}
+	}
+	if err != nil {
+		if image.Key != old.Key {
+			h.deleteAirportImage(ctx, image)
+		}
+		handleResponse(c, http.StatusInternalServerError, "Image does not save: "+err.Error())
+		return
+	}
+
+	resp, err := h.strg.Airport().UpdateImage(models.UpdateAirportImage{
+		AirportId:   id,
+		Image:       "/airport/" + id + "/image",
+		Key:         image.Key,
+		ContentType: image.ContentType,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Airport does not update: "+err.Error())
+		return
+	}
+
+	if old.Key != "" && old.Key != image.Key {
+		h.deleteAirportImage(ctx, *old)
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// AirportImageGet godoc
+// @Summary Get Airport image
+// @Description Get the uploaded image of the airport or one of its thumbnails
+// @Tags Airport
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/gif
+// @Param id path string true "Airport ID"
+// @Param size query string false "original | small | medium"
+// @Param If-None-Match header string false "ETag of the image the client has"
+// @Param If-Modified-Since header string false "Last-Modified of the image the client has"
+// @Success 200 {file} file "Image"
+// @Success 304 {string} string "Not Modified"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 404 {object} Response{data=string} "Airport or image does not exist"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /airport/{id}/image [get]
+func (h *Handler) AirportImageGet(c *gin.Context) {
+	id := c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id not valid uuid")
+		return
+	}
+
+	image, ok := h.getAirportImage(c, id)
+	if !ok {
+		return
+	}
+	if image.Key == "" {
+		handleResponse(c, http.StatusNotFound, "Airport has no image")
+		return
+	}
+
+	size := c.DefaultQuery("size", airportImageOriginal)
+	contentType := image.ContentType
+	if size != airportImageOriginal {
+		if !isImageSize(size) {
+			handleResponse(c, http.StatusBadRequest, "size must be original, small or medium")
+			return
+		}
+		contentType = "image/jpeg"
+	}
+
+	etag := `"` + path.Base(image.Key) + "-" + size + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=86400")
+	updatedAt, err := time.Parse(time.RFC3339Nano, image.UpdatedAt)
+	if err == nil {
+		c.Header("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c, etag, updatedAt) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	src, err := h.files.Blobs().Get(c.Request.Context(), airportImageKey(*image, size))
+	if err == blob.ErrNotFound {
+		handleResponse(c, http.StatusNotFound, "Image does not exist")
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Image does not exist: "+err.Error())
+		return
+	}
+	defer src.Close()
+
+	c.DataFromReader(http.StatusOK, -1, contentType, src, nil)
+}
+
+// notModified reports whether the client already has the image of etag,
+// last modified at updatedAt. If-None-Match wins over If-Modified-Since, a
+// zero updatedAt never matches the latter.
+func notModified(c *gin.Context, etag string, updatedAt time.Time) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		return match == etag
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || updatedAt.IsZero() {
+		return false
+	}
+
+	// Last-Modified only has whole seconds.
+	return !updatedAt.Truncate(time.Second).After(since)
+}
+
+// getAirportImage loads the image of airport id. It writes the error
+// response and returns false if there is no such airport.
+func (h *Handler) getAirportImage(c *gin.Context, id string) (*models.AirportImage, bool) {
+	image, err := h.strg.Airport().GetImage(models.AirportPrimaryKey{Id: id})
+	if err == sql.ErrNoRows {
+		handleResponse(c, http.StatusNotFound, "Airport does not exist")
+		return nil, false
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Airport does not exist: "+err.Error())
+		return nil, false
+	}
+
+	return image, true
+}
+
+// deleteAirportImage removes the blobs of image. Failures are only logged,
+// the airport itself is already consistent.
+func (h *Handler) deleteAirportImage(ctx context.Context, image models.AirportImage) {
+	keys := []string{airportImageKey(image, airportImageOriginal)}
+	for _, size := range imaging.Sizes {
+		keys = append(keys, airportImageKey(image, size.Name))
+	}
+
+	for _, key := range keys {
+		if err := h.files.Blobs().Delete(ctx, key); err != nil {
+			log.Println(config.Error, "airport image", key, "does not delete:", err)
+		}
+	}
+}
+
+// airportImageKey is the blob key of one size of image. Thumbnails are
+// always JPEG, the original keeps its type.
+func airportImageKey(image models.AirportImage, size string) string {
+	if size == airportImageOriginal {
+		return image.Key + "/" + airportImageOriginal + imaging.Types[image.ContentType]
+	}
+
+	return image.Key + "/" + size + ".jpg"
+}
+
+func isImageSize(name string) bool {
+	for _, size := range imaging.Sizes {
+		if size.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
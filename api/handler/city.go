@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"ret/api/models"
 	"ret/pkg/helpers"
+	"ret/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -68,9 +70,16 @@ func (h *Handler) CityGetById(c *gin.Context) {
 // @Tags City
 // @Accept json
 // @Produce json
-// @Param limit query int false "Limit"
+// @Param limit query int false "Limit, 10 by default and at most 100"
 // @Param offset query int false "Offset"
+// @Param cursor query string false "next_cursor or prev_cursor of another page, replaces offset"
+// @Param country_id query string false "Country ID"
+// @Param timezone_id query string false "Timezone ID"
+// @Param title query string false "Title prefix"
+// @Param sort query string false "Sort by title, city_code, country_name, created_at or updated_at; comma separated, - for descending"
 // @Success 200 {object} Response{data=models.GetListCityResponse} "GetListCityResponseBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
 // @Router /city [get]
 func (h *Handler) CityGetList(c *gin.Context) {
 	var city models.GetListCityRequest
@@ -80,6 +89,10 @@ func (h *Handler) CityGetList(c *gin.Context) {
 		return
 	}
 	resp, err := h.strg.City().GetList(city)
+	if errors.Is(err, storage.ErrInvalidListQuery) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		handleResponse(c, 500, "city does not exist: "+err.Error())
 		return
@@ -158,39 +171,26 @@ func (h *Handler) CityDelete(c *gin.Context) {
 // @Tags City
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "Файл JSON с городами"
-// @Success 200 {string} string "Файл успешно загружен"
+// @Param file formData file true "Файл JSON, NDJSON, GeoJSON, CSV или XLSX с городами, можно сжатый gzip (кроме XLSX)"
+// @Param mode query string false "strict | partial"
+// @Param strategy query string false "insert-only | upsert | skip-existing | replace-all"
+// @Param loader query string false "row | copy (COPY в промежуточную таблицу)"
+// @Param profile query string false "ID профиля сопоставления полей, см. /mapping-profiles"
+// @Param on_missing query string false "reject | null | stub: что делать со ссылками (страна, город, часовой пояс), которые не найдены ни по guid, ни по коду или названию"
+// @Param sheet query string false "Лист книги XLSX, по умолчанию лист с именем таблицы или первый"
+// @Param dry_run query bool false "Только проверить файл, ничего не сохраняя: строки пишутся в транзакции, которая затем откатывается, а заглушки (on_missing=stub) и удаления replace-all только подсчитываются"
+// @Param force query bool false "Импортировать файл заново, даже если он уже был загружен"
+// @Param X-Uploaded-By header string false "Кто загружает файл, сохраняется в истории импорта"
+// @Param Idempotency-Key header string false "Повторный запрос с тем же ключом вернёт ту же задачу"
+// @Success 200 {object} Response{data=models.ImportResult} "Результат проверки (dry_run) или уже созданная задача импорта"
+// @Success 202 {object} Response{data=models.ImportJob} "Задача импорта создана"
 // @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 413 {object} Response{data=models.UploadError} "Файл больше допустимого размера"
+// @Failure 415 {object} Response{data=models.UploadError} "Неподдерживаемый формат файла"
+// @Failure 422 {object} Response{data=string} "Idempotency-Key использован для другого файла"
+// @Failure 503 {object} Response{data=string} "Очередь импорта переполнена"
 // @Failure 500 {object} Response{data=string} "Ошибка сервера"
 // @Router /upload [post]
 func (h *Handler) UploadCities(c *gin.Context) {
-
-	file, err := c.FormFile("file")
-	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Ошибка при получении файла: "+err.Error())
-		return
-	}
-
-	if file.Header.Get("Content-Type") != "application/json" {
-		handleResponse(c, http.StatusBadRequest, "Неверный формат файла.  загрузите файл JSON.")
-		return
-	}
-
-	uploadPath := "uploads/"
-
-	err = c.SaveUploadedFile(file, uploadPath+file.Filename)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, "Ошибка при сохранении файла: "+err.Error())
-		return
-	}
-
-	filePath := uploadPath + file.Filename
-
-	err = h.strg.City().ImportFromFile(filePath)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, "Ошибка при импорте данных: "+err.Error())
-		return
-	}
-
-	handleResponse(c, http.StatusOK, "Файл успешно загружен ")
+	h.enqueueImport(c, models.ImportEntityCity)
 }
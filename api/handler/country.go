@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"ret/api/models"
 	"ret/pkg/helpers"
+	"ret/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -68,9 +70,15 @@ func (h *Handler) CountryGetById(c *gin.Context) {
 // @Tags Country
 // @Accept json
 // @Produce json
-// @Param limit query int false "Limit"
+// @Param limit query int false "Limit, 10 by default and at most 100"
 // @Param offset query int false "Offset"
+// @Param cursor query string false "next_cursor or prev_cursor of another page, replaces offset"
+// @Param continent query string false "Continent"
+// @Param code query string false "Country code"
+// @Param sort query string false "Sort by title, code, continent, created_at or updated_at; comma separated, - for descending"
 // @Success 200 {object} Response{data=models.GetListCountryResponse} "GetListCountryResponseBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
 // @Router /country [get]
 func (h *Handler) CountryGetList(c *gin.Context) {
 	var country models.GetListCountryRequest
@@ -80,6 +88,10 @@ func (h *Handler) CountryGetList(c *gin.Context) {
 		return
 	}
 	resp, err := h.strg.Country().GetList(country)
+	if errors.Is(err, storage.ErrInvalidListQuery) {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		handleResponse(c, 500, "Country does not exist: "+err.Error())
 		return
@@ -152,44 +164,3 @@ func (h *Handler) CountryDelete(c *gin.Context) {
 
 	handleResponse(c, http.StatusNoContent, nil)
 }
-
-// UploadCountries godoc
-// @Summary Загрузка стран
-// @Description Загрузка стран из файла
-// @Tags Country
-// @Accept multipart/form-data
-// @Produce json
-// @Param file formData file true "Файл JSON с городами"
-// @Success 200 {string} string "Файл успешно загружен"
-// @Failure 400 {object} Response{data=string} "Неверный аргумент"
-// @Failure 500 {object} Response{data=string} "Ошибка сервера"
-// @Router /upload/{table_slug} [post]
-func (h *Handler) UploadCountry(c *gin.Context) {
-
-	file, err := c.FormFile("file")
-	if err != nil {
-		handleResponse(c, http.StatusBadRequest, "Ошибка при получении файла: "+err.Error())
-		return
-	}
-
-	if file.Header.Get("Content-Type") != "application/json" {
-		handleResponse(c, http.StatusBadRequest, "Неверный формат файла. загрузите файл JSON.")
-		return
-	}
-
-	uploadPath := "uploads/"
-	err = c.SaveUploadedFile(file, uploadPath+file.Filename)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, "Ошибка при сохранении файла: "+err.Error())
-		return
-	}
-
-	filePath := uploadPath + file.Filename
-	err = h.strg.Country().ImportFromFileCountry(filePath)
-	if err != nil {
-		handleResponse(c, http.StatusInternalServerError, "Ошибка при импорте данных: "+err.Error())
-		return
-	}
-
-	handleResponse(c, http.StatusOK, "Файл успешно загружен")
-}
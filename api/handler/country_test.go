@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"ret/config"
+	"ret/storage"
+	"ret/storage/postgres"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listStore serves the country repo without a database, enough for list
+// requests that fail before their query runs.
+type listStore struct {
+	storage.StorageI
+}
+
+func (listStore) Country() storage.CountryRepoI {
+	return postgres.NewCountryRepo(nil)
+}
+
+func TestCountryGetListBadQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := NewHandler(&config.Config{}, listStore{}, nil, nil)
+	r := gin.New()
+	r.GET("/country", h.CountryGetList)
+
+	for _, query := range []string{
+		"cursor=!!!",
+		"cursor=eyJzIjoidGl0bGUifQ",
+		"sort=population",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/country?"+query, nil))
+
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusBadRequest || resp.Status != http.StatusBadRequest {
+			t.Errorf("%s: status %d, %d, want 400: %v", query, w.Code, resp.Status, resp.Data)
+		}
+	}
+}
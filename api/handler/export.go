@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"ret/api/models"
+	"ret/config"
+	"ret/pkg/dataset"
+	"ret/storage"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+)
+
+// exportFlushRows is how many rows are sent in one chunk of an export.
+const exportFlushRows = 1000
+
+var exportContentTypes = map[string]string{
+	dataset.FormatJSON:    "application/json; charset=utf-8",
+	dataset.FormatNDJSON:  "application/x-ndjson; charset=utf-8",
+	dataset.FormatCSV:     "text/csv; charset=utf-8",
+	dataset.FormatGeoJSON: "application/geo+json; charset=utf-8",
+	dataset.FormatXLSX:    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// exportBufferField is the field with the radius of the buffer polygons of
+// a GeoJSON export.
+const exportBufferField = "radius"
+
+// Export godoc
+// @Summary Export table
+// @Description Stream the rows of a table as a file that /upload/{table_slug} takes back. The filters and the sort of the list endpoint of the table apply. The fields are the ones GET /upload lists for the table. GeoJSON is a FeatureCollection of points for the tables with latitude and longitude (city, airport). An error after the file has started drops the connection, so the file ends without the end of the chunked body and the client sees it as incomplete.
+// @Tags Export
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/geo+json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param table_slug path string true "country | city | airport | timezone"
+// @Param format query string false "json | csv | ndjson | geojson | xlsx"
+// @Param buffer query bool false "GeoJSON only: add a polygon of the airport radius in meters around each point"
+// @Param country_id query string false "city, airport: Country ID"
+// @Param city_id query string false "airport: City ID"
+// @Param timezone_id query string false "city: Timezone ID"
+// @Param continent query string false "country: Continent"
+// @Param code query string false "country, airport: Code"
+// @Param title query string false "city: Title prefix"
+// @Param product_count_min query int false "airport: Minimum product count"
+// @Param product_count_max query int false "airport: Maximum product count"
+// @Param sort query string false "Fields of the list endpoint of the table; comma separated, - for descending"
+// @Success 200 {file} file "Table rows"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 404 {object} Response{data=string} "Unknown table"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /export/{table_slug} [get]
+func (h *Handler) Export(c *gin.Context) {
+	var req = models.ExportRequest{Entity: c.Param("table_slug")}
+
+	schema, ok := h.strg.Import().Schema(req.Entity)
+	if !ok {
+		handleResponse(c, http.StatusNotFound, "unknown table: "+req.Entity)
+		return
+	}
+
+	if err := bindExportFilters(c, &req); err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while binding data: "+err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", dataset.FormatJSON)
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		handleResponse(c, http.StatusBadRequest, "format must be json, csv, ndjson, geojson or xlsx")
+		return
+	}
+
+	fields := schemaFields(*schema)
+
+	opts := dataset.WriterOptions{Sheet: req.Entity}
+	if format == dataset.FormatGeoJSON {
+		if !hasField(fields, dataset.LatitudeField) || !hasField(fields, dataset.LongitudeField) {
+			handleResponse(c, http.StatusBadRequest, "geojson export needs latitude and longitude, "+req.Entity+" has none")
+			return
+		}
+		if cast.ToBool(c.Query("buffer")) && hasField(fields, exportBufferField) {
+			opts.Buffer = exportBufferField
+		}
+	}
+
+	w, err := dataset.NewWriter(c.Writer, format, fields, opts)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Export does not start: "+err.Error())
+		return
+	}
+
+	h.streamExport(c, req.Entity+"."+format, contentType, w, func(each func(dataset.Record) error) error {
+		return h.strg.Import().Export(c.Request.Context(), req, each)
+	})
+}
+
+// ExportWorkbook godoc
+// @Summary Export workbook
+// @Description Stream an XLSX workbook with a sheet per table, which /upload/archive takes back. Numbers, booleans and timestamps get typed cells. An error after the file has started drops the connection.
+// @Tags Export
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param tables query string false "Comma separated tables, all by default: country,city,airport,timezone"
+// @Success 200 {file} file "Workbook"
+// @Failure 404 {object} Response{data=string} "Unknown table"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /export/workbook [get]
+func (h *Handler) ExportWorkbook(c *gin.Context) {
+	var schemas []models.ImportSchema
+	if tables := c.Query("tables"); tables != "" {
+		for _, table := range strings.Split(tables, ",") {
+			schema, ok := h.strg.Import().Schema(strings.TrimSpace(table))
+			if !ok {
+				handleResponse(c, http.StatusNotFound, "unknown table: "+table)
+				return
+			}
+			schemas = append(schemas, *schema)
+		}
+	} else {
+		schemas = h.strg.Import().Schemas()
+	}
+
+	book := dataset.NewWorkbook(c.Writer)
+	h.streamExport(c, "workbook.xlsx", exportContentTypes[dataset.FormatXLSX], book, func(each func(dataset.Record) error) error {
+		for _, schema := range schemas {
+			if err := book.AddSheet(schema.Slug, schemaFields(schema)); err != nil {
+				return err
+			}
+
+			err := h.strg.Import().Export(c.Request.Context(), models.ExportRequest{Entity: schema.Slug}, each)
+			if err != nil {
+				return fmt.Errorf("%s: %w", schema.Slug, err)
+			}
+		}
+		return nil
+	})
+}
+
+// exportWriter is a dataset.Writer or a dataset.Workbook.
+type exportWriter interface {
+	Write(record dataset.Record) error
+	Flush() error
+	Close() error
+}
+
+// streamExport sends the records export passes on to w as the file name,
+// flushing them every exportFlushRows rows. The buffers of w and of the
+// response may fill up and reach the client before that, so only an error
+// before the first byte is written gets a response of its own. A later one
+// drops the connection.
+func (h *Handler) streamExport(c *gin.Context, name, contentType string, w exportWriter, export func(each func(dataset.Record) error) error) {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename="+name)
+
+	var count int
+	err := export(func(record dataset.Record) error {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushRows == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+
+		return nil
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			if errors.Is(err, storage.ErrInvalidListQuery) {
+				handleResponse(c, http.StatusBadRequest, err.Error())
+				return
+			}
+			handleResponse(c, http.StatusInternalServerError, "Export does not complete: "+err.Error())
+			return
+		}
+
+		// The status is sent already. Ending the body normally would pass
+		// the truncated file off as complete.
+		log.Println(config.Error, "export", name, "stopped after", count, "rows:", err)
+		abortResponse(c)
+		return
+	}
+
+	c.Writer.Flush()
+}
+
+// abortResponse closes the connection of a response under way without
+// ending its chunked body, so the client reads it as broken.
+func abortResponse(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		log.Println(config.Error, "response is not aborted:", err)
+		return
+	}
+	conn.Close()
+}
+
+// bindExportFilters reads the list filters of the entity of req from the
+// query.
+func bindExportFilters(c *gin.Context, req *models.ExportRequest) error {
+	switch req.Entity {
+	case models.ImportEntityCountry:
+		return c.ShouldBindQuery(&req.Country)
+	case models.ImportEntityCity:
+		return c.ShouldBindQuery(&req.City)
+	case models.ImportEntityAirport:
+		return c.ShouldBindQuery(&req.Airport)
+	}
+
+	return nil
+}
+
+func schemaFields(schema models.ImportSchema) []string {
+	fields := make([]string, 0, len(schema.Fields))
+	for _, field := range schema.Fields {
+		fields = append(fields, field.Name)
+	}
+
+	return fields
+}
+
+func hasField(fields []string, name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"log"
 	"ret/config"
+	"ret/pkg/filestore"
 	"ret/storage"
-	"log"
+	"ret/worker"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +14,9 @@ import (
 type Handler struct {
 	cfg  *config.Config
 	strg storage.StorageI
+	pool *worker.Pool
+
+	files *filestore.Store
 }
 
 // Response - Json model response
@@ -21,10 +26,13 @@ type Response struct {
 	Data        interface{} `json:"data"`
 }
 
-func NewHandler(cfg *config.Config, strg storage.StorageI) *Handler {
+func NewHandler(cfg *config.Config, strg storage.StorageI, pool *worker.Pool, files *filestore.Store) *Handler {
 	return &Handler{
 		cfg:  cfg,
 		strg: strg,
+		pool: pool,
+
+		files: files,
 	}
 }
 
@@ -0,0 +1,511 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"ret/api/models"
+	"ret/config"
+	"ret/pkg/dataset"
+	"ret/pkg/filestore"
+	"ret/pkg/helpers"
+	"ret/storage"
+	"ret/worker"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+// ImportJobGetById godoc
+// @Summary Get Import Job by ID
+// @Description Get status and progress of an import job
+// @Tags ImportJob
+// @Accept json
+// @Produce json
+// @Param id path string true "Import Job ID"
+// @Success 200 {object} Response{data=models.ImportJob} "ImportJobBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /import-jobs/{id} [get]
+func (h *Handler) ImportJobGetById(c *gin.Context) {
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.ImportJob().GetById(models.ImportJobPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, 500, "Import job does not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// ImportJobGetList godoc
+// @Summary Get List of Import Jobs
+// @Description Import history, newest first
+// @Tags ImportJob
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Param entity query string false "country | city | airport | timezone"
+// @Success 200 {object} Response{data=models.GetListImportJobResponse} "GetListImportJobResponseBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /import-jobs [get]
+func (h *Handler) ImportJobGetList(c *gin.Context) {
+	var req models.GetListImportJobRequest
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while binding data: "+err.Error())
+		return
+	}
+
+	resp, err := h.strg.ImportJob().GetList(req)
+	if err != nil {
+		handleResponse(c, 500, "Import jobs do not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// ImportJobCancel godoc
+// @Summary Cancel Import Job
+// @Description Cancel a queued or running import job
+// @Tags ImportJob
+// @Accept json
+// @Produce json
+// @Param id path string true "Import Job ID"
+// @Success 202 {object} Response{data=models.ImportJob} "ImportJobBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /import-jobs/{id}/cancel [post]
+func (h *Handler) ImportJobCancel(c *gin.Context) {
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	// A running job is marked cancelled by its worker once the import
+	// transaction has been rolled back.
+	if h.pool.Cancel(id) {
+		resp, err := h.strg.ImportJob().GetById(models.ImportJobPrimaryKey{Id: id})
+		if err != nil {
+			handleResponse(c, 500, "Import job does not exist: "+err.Error())
+			return
+		}
+
+		handleResponse(c, http.StatusAccepted, resp)
+		return
+	}
+
+	resp, err := h.strg.ImportJob().Update(models.UpdateImportJob{Guid: id, Status: models.ImportJobCancelled})
+	if err != nil {
+		handleResponse(c, 500, "Import job does not cancel: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// ImportJobRollback godoc
+// @Summary Roll back Import Job
+// @Description Delete the rows a completed import inserted and restore the rows it updated or deleted. Refused with 409 if any of those rows has since been changed, by a later import or by hand.
+// @Tags ImportJob
+// @Accept json
+// @Produce json
+// @Param id path string true "Import Job ID"
+// @Success 200 {object} Response{data=models.ImportRollback} "ImportRollbackBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 409 {object} Response{data=string} "The job did not complete or its rows were changed since"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /import-jobs/{id}/rollback [post]
+func (h *Handler) ImportJobRollback(c *gin.Context) {
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.ImportJob().Rollback(c.Request.Context(), models.ImportJobPrimaryKey{Id: id})
+	if errors.Is(err, storage.ErrRollbackConflict) {
+		handleResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleResponse(c, 500, "Import job does not roll back: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// ImportJobGetReport godoc
+// @Summary Get Import Job report
+// @Description Get the rows rejected by an import job as JSON or as a CSV file. Only the first 1000 errors are kept, count is every rejected row
+// @Tags ImportJob
+// @Accept json
+// @Produce json
+// @Produce text/csv
+// @Param id path string true "Import Job ID"
+// @Param format query string false "json | csv"
+// @Success 200 {object} Response{data=models.ImportReport} "ImportReportBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /import-jobs/{id}/report [get]
+func (h *Handler) ImportJobGetReport(c *gin.Context) {
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		handleResponse(c, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+
+	resp, err := h.strg.ImportJob().GetReport(models.ImportJobPrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, 500, "Import job does not exist: "+err.Error())
+		return
+	}
+
+	if format == "json" {
+		handleResponse(c, http.StatusOK, resp)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename=import-"+id+"-report.csv")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"file", "index", "guid", "field", "reason"})
+	for _, rowErr := range resp.Errors {
+		w.Write([]string{rowErr.File, cast.ToString(rowErr.Index), rowErr.Guid, rowErr.Field, rowErr.Reason})
+	}
+	w.Flush()
+}
+
+// enqueueImport saves the uploaded file and queues an import job for it.
+func (h *Handler) enqueueImport(c *gin.Context, entity string) {
+
+	req, ok := h.importOptions(c, entity)
+	if !ok {
+		return
+	}
+
+	// Stop reading a body that is over the limit instead of spooling it to
+	// disk first. The slack covers the multipart headers.
+	limit := h.cfg.UploadLimit(entity)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+multipartSlack)
+
+	file, err := c.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		uploadTooLarge(c, limit)
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Ошибка при получении файла: "+err.Error())
+		return
+	}
+	if file.Size > limit {
+		uploadTooLarge(c, limit)
+		return
+	}
+
+	req.FileName, err = filestore.CleanName(file.Filename)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Недопустимое имя файла: "+file.Filename)
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Ошибка при получении файла: "+err.Error())
+		return
+	}
+	defer src.Close()
+
+	head, err := dataset.Head(src)
+	if err == nil {
+		_, err = src.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Ошибка при получении файла: "+err.Error())
+		return
+	}
+
+	req.Format, ok = importFormat(c, entity, req.FileName, head)
+	if !ok {
+		return
+	}
+
+	if cast.ToBool(c.Query("dry_run")) {
+		tmp, err := os.CreateTemp("", "dry-run-*")
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, "Ошибка при сохранении файла: "+err.Error())
+			return
+		}
+		defer os.Remove(tmp.Name())
+
+		_, err = io.Copy(tmp, src)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, "Ошибка при сохранении файла: "+err.Error())
+			return
+		}
+
+		h.dryRunImport(c, req, tmp.Name())
+		return
+	}
+
+	stored, err := h.files.Save(c.Request.Context(), src, limit)
+	if err == filestore.ErrTooLarge {
+		uploadTooLarge(c, limit)
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при сохранении файла: "+err.Error())
+		return
+	}
+
+	req.FilePath = stored.Key
+	req.Checksum = stored.Checksum
+	h.submitImport(c, req)
+}
+
+// importOptions reads the import options of an upload to entity from the
+// query and headers. It writes the error response and returns false if one
+// is invalid.
+func (h *Handler) importOptions(c *gin.Context, entity string) (models.CreateImportJob, bool) {
+
+	if _, ok := h.strg.Import().Schema(entity); !ok && entity != models.ImportEntityArchive {
+		handleResponse(c, http.StatusNotFound, "Неизвестная таблица: "+entity)
+		return models.CreateImportJob{}, false
+	}
+
+	mode := c.DefaultQuery("mode", models.ImportModeStrict)
+	if mode != models.ImportModeStrict && mode != models.ImportModePartial {
+		handleResponse(c, http.StatusBadRequest, "Неверный режим импорта: "+mode)
+		return models.CreateImportJob{}, false
+	}
+
+	strategy := c.DefaultQuery("strategy", models.ImportStrategyInsertOnly)
+	switch strategy {
+	case models.ImportStrategyInsertOnly, models.ImportStrategyUpsert, models.ImportStrategySkipExisting, models.ImportStrategyReplaceAll:
+	default:
+		handleResponse(c, http.StatusBadRequest, "Неверная стратегия импорта: "+strategy)
+		return models.CreateImportJob{}, false
+	}
+
+	loader := c.DefaultQuery("loader", models.ImportLoaderRow)
+	if loader != models.ImportLoaderRow && loader != models.ImportLoaderCopy {
+		handleResponse(c, http.StatusBadRequest, "Неверный загрузчик: "+loader)
+		return models.CreateImportJob{}, false
+	}
+
+	onMissing := c.Query("on_missing")
+	switch onMissing {
+	case "", models.ImportOnMissingReject, models.ImportOnMissingNull, models.ImportOnMissingStub:
+	default:
+		handleResponse(c, http.StatusBadRequest, "Неверное действие для ненайденных ссылок: "+onMissing)
+		return models.CreateImportJob{}, false
+	}
+
+	profile := c.Query("profile")
+	if profile != "" {
+		if !helpers.IsValidUUID(profile) {
+			handleResponse(c, http.StatusBadRequest, "Неверный профиль сопоставления: "+profile)
+			return models.CreateImportJob{}, false
+		}
+
+		mapping, err := h.strg.MappingProfile().GetById(models.MappingProfilePrimaryKey{Id: profile})
+		if err == sql.ErrNoRows {
+			handleResponse(c, http.StatusNotFound, "Профиль сопоставления не найден: "+profile)
+			return models.CreateImportJob{}, false
+		}
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, "Ошибка при получении профиля сопоставления: "+err.Error())
+			return models.CreateImportJob{}, false
+		}
+		if mapping.Entity != "" && mapping.Entity != entity {
+			handleResponse(c, http.StatusBadRequest, "Профиль сопоставления предназначен для таблицы "+mapping.Entity)
+			return models.CreateImportJob{}, false
+		}
+	}
+
+	return models.CreateImportJob{
+		Entity:           entity,
+		UploadedBy:       c.GetHeader("X-Uploaded-By"),
+		IdempotencyKey:   c.GetHeader("Idempotency-Key"),
+		Mode:             mode,
+		Strategy:         strategy,
+		Loader:           loader,
+		MappingProfileId: profile,
+		OnMissing:        onMissing,
+		Sheet:            c.Query("sheet"),
+	}, true
+}
+
+// importFormat detects the format of a file uploaded to entity from its
+// first bytes, see dataset.Detect; with a nil head it goes by the name only.
+// Archives are only accepted by /upload/archive. It writes a 415 response
+// and returns false if the format does not fit.
+func importFormat(c *gin.Context, entity, fileName string, head []byte) (string, bool) {
+	format, err := dataset.Detect(fileName, head)
+	if err != nil {
+		unsupportedUpload(c, "Неверный формат файла, загрузите JSON, NDJSON, GeoJSON, CSV или XLSX: "+err.Error(), "")
+		return "", false
+	}
+
+	// A workbook goes to a single table or, as an archive, sheet by sheet.
+	archive := entity == models.ImportEntityArchive
+	if archive && format != dataset.FormatZip && format != dataset.FormatXLSX {
+		unsupportedUpload(c, "Неверный формат файла. загрузите ZIP архив или книгу XLSX.", format)
+		return "", false
+	}
+	if !archive && format == dataset.FormatZip {
+		unsupportedUpload(c, "Архивы загружаются через /upload/archive.", format)
+		return "", false
+	}
+
+	return format, true
+}
+
+// multipartSlack is what a multipart body may carry on top of the file.
+const multipartSlack = 1 << 20
+
+func uploadTooLarge(c *gin.Context, limit int64) {
+	handleResponse(c, http.StatusRequestEntityTooLarge, models.UploadError{
+		Code:    models.UploadErrorTooLarge,
+		Message: fmt.Sprintf("Файл больше допустимых %d байт", limit),
+		Limit:   limit,
+	})
+}
+
+func unsupportedUpload(c *gin.Context, message, format string) {
+	handleResponse(c, http.StatusUnsupportedMediaType, models.UploadError{
+		Code:    models.UploadErrorUnsupported,
+		Message: message,
+		Format:  format,
+	})
+}
+
+// submitImport queues an import job for the stored file of req, or answers
+// with the job it duplicates unless the upload is forced. It reports whether
+// the answer is a job rather than an error.
+func (h *Handler) submitImport(c *gin.Context, req models.CreateImportJob) bool {
+	if req.IdempotencyKey != "" || !cast.ToBool(c.Query("force")) {
+		if replied, ok := h.replayImport(c, req); replied {
+			return ok
+		}
+	}
+
+	job, err := h.strg.ImportJob().Create(req)
+	if err != nil {
+		// Lost a race against the same Idempotency-Key.
+		if req.IdempotencyKey != "" {
+			if replied, ok := h.replayImport(c, req); replied {
+				return ok
+			}
+		}
+
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при создании задачи импорта: "+err.Error())
+		return false
+	}
+
+	err = h.pool.Submit(job.Guid)
+	if err != nil {
+		// The job never existed for the client, a retry with the same
+		// Idempotency-Key must create it anew.
+		if err := h.strg.ImportJob().Delete(models.ImportJobPrimaryKey{Id: job.Guid}); err != nil {
+			log.Println(config.Error, "import job", job.Guid, "is not deleted:", err)
+		}
+		handleResponse(c, http.StatusServiceUnavailable, "Очередь импорта переполнена, попробуйте позже")
+		return false
+	}
+
+	handleResponse(c, http.StatusAccepted, job)
+	return true
+}
+
+// replayImport answers with the job req duplicates, if there is one: the job
+// with the same Idempotency-Key, or else the last job that imported the same
+// file into the same entity with the same options and did not fail. It
+// reports whether a response was written and whether that is the job.
+func (h *Handler) replayImport(c *gin.Context, req models.CreateImportJob) (replied, ok bool) {
+	job, err := h.strg.ImportJob().GetDuplicate(req)
+	if err == sql.ErrNoRows {
+		return false, false
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при поиске задачи импорта: "+err.Error())
+		return true, false
+	}
+
+	if job.Entity != req.Entity || job.Checksum != req.Checksum {
+		handleResponse(c, http.StatusUnprocessableEntity, "Idempotency-Key уже использован для другого файла")
+		return true, false
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	handleResponse(c, http.StatusOK, job)
+	return true, true
+}
+
+// dryRunImport validates the file at filePath against the database and
+// returns what an import would do. The rows are written in a transaction
+// that is rolled back, so their constraints and triggers are checked; stubs
+// and the deletes of replace-all are only counted.
+func (h *Handler) dryRunImport(c *gin.Context, req models.CreateImportJob, filePath string) {
+
+	var mapping *models.MappingRules
+	if req.MappingProfileId != "" {
+		profile, err := h.strg.MappingProfile().GetById(models.MappingProfilePrimaryKey{Id: req.MappingProfileId})
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, "Ошибка при получении профиля сопоставления: "+err.Error())
+			return
+		}
+		mapping = &profile.Rules
+	}
+
+	// The batch id only has to be unique, nothing is committed.
+	resp, err := worker.Import(c.Request.Context(), h.strg, req.Entity, models.ImportRequest{
+		FilePath:     filePath,
+		Format:       req.Format,
+		Aliases:      h.cfg.ImportColumnAliases,
+		KeepLegacyId: h.cfg.ImportKeepLegacyId,
+		Mode:         models.ImportModePartial,
+		Strategy:     req.Strategy,
+		Loader:       req.Loader,
+		Mapping:      mapping,
+		OnMissing:    req.OnMissing,
+		Sheet:        req.Sheet,
+		UnpackLimit:  h.cfg.UploadLimit(req.Entity),
+		BatchId:      uuid.New().String(),
+		DryRun:       true,
+	})
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Ошибка при проверке файла: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"ret/api/models"
+	"ret/pkg/dataset"
+	"ret/pkg/helpers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateMappingProfile godoc
+// @Summary Create Mapping Profile
+// @Description Create a profile that renames, drops, transforms (trim, upper, lower, number) and defaults the fields of uploaded files before they are bound. Pick it per upload with ?profile=<id>.
+// @Tags MappingProfile
+// @Accept json
+// @Produce json
+// @Param object body models.CreateMappingProfile true "CreateMappingProfileRequestBody"
+// @Success 201 {object} Response{data=models.MappingProfile} "MappingProfileBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /mapping-profiles [post]
+func (h *Handler) CreateMappingProfile(c *gin.Context) {
+	var profile = models.CreateMappingProfile{}
+	err := c.ShouldBindJSON(&profile)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "ShouldBindJSON err: "+err.Error())
+		return
+	}
+
+	if err := h.checkMappingProfile(profile.Name, profile.Entity, profile.Rules); err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.strg.MappingProfile().Create(profile)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Mapping profile does not create: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusCreated, resp)
+}
+
+// MappingProfileGetById godoc
+// @Summary Get Mapping Profile by ID
+// @Description Get Mapping Profile by ID
+// @Tags MappingProfile
+// @Accept json
+// @Produce json
+// @Param id path string true "Mapping Profile ID"
+// @Success 200 {object} Response{data=models.MappingProfile} "MappingProfileBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 404 {object} Response{data=string} "Mapping profile does not exist"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /mapping-profiles/{id} [get]
+func (h *Handler) MappingProfileGetById(c *gin.Context) {
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.MappingProfile().GetById(models.MappingProfilePrimaryKey{Id: id})
+	if err == sql.ErrNoRows {
+		handleResponse(c, http.StatusNotFound, "Mapping profile does not exist")
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Mapping profile does not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// MappingProfileGetList godoc
+// @Summary Get List of Mapping Profiles
+// @Description Mapping profiles by name. Filtered by entity the profiles that fit any entity are listed too.
+// @Tags MappingProfile
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Param entity query string false "country | city | airport | timezone"
+// @Success 200 {object} Response{data=models.GetListMappingProfileResponse} "GetListMappingProfileResponseBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /mapping-profiles [get]
+func (h *Handler) MappingProfileGetList(c *gin.Context) {
+	var req models.GetListMappingProfileRequest
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while binding data: "+err.Error())
+		return
+	}
+
+	resp, err := h.strg.MappingProfile().GetList(req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Mapping profiles do not exist: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
+
+// MappingProfileUpdate godoc
+// @Router /mapping-profiles/{id} [put]
+// @Summary Update Mapping Profile
+// @Description Update Mapping Profile. Queued imports pick up the new rules.
+// @Tags MappingProfile
+// @Accept json
+// @Produce json
+// @Param id path string true "Mapping Profile ID"
+// @Param object body models.UpdateMappingProfile true "UpdateMappingProfileRequestBody"
+// @Success 202 {object} Response{data=models.MappingProfile} "MappingProfileBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 404 {object} Response{data=string} "Mapping profile does not exist"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) MappingProfileUpdate(c *gin.Context) {
+	var profile = models.UpdateMappingProfile{}
+
+	id := c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id not valid uuid")
+		return
+	}
+
+	err := c.ShouldBindJSON(&profile)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	profile.Guid = id
+
+	if err := h.checkMappingProfile(profile.Name, profile.Entity, profile.Rules); err != nil {
+		handleResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.strg.MappingProfile().Update(profile)
+	if err == sql.ErrNoRows {
+		handleResponse(c, http.StatusNotFound, "Mapping profile does not exist")
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Mapping profile does not update: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusAccepted, resp)
+}
+
+// MappingProfileDelete godoc
+// @Router /mapping-profiles/{id} [delete]
+// @Summary Delete Mapping Profile
+// @Description Delete Mapping Profile. Queued imports that use it fail.
+// @Tags MappingProfile
+// @Accept json
+// @Produce json
+// @Param id path string true "Mapping Profile ID"
+// @Success 204 {string} models.NoContent ""
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) MappingProfileDelete(c *gin.Context) {
+	id := c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id not valid uuid")
+		return
+	}
+
+	err := h.strg.MappingProfile().Delete(models.MappingProfilePrimaryKey{Id: id})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Mapping profile does not delete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusNoContent, nil)
+}
+
+// checkMappingProfile validates a profile before it is saved.
+func (h *Handler) checkMappingProfile(name, entity string, rules models.MappingRules) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	if _, ok := h.strg.Import().Schema(entity); !ok && entity != "" {
+		return errors.New("unknown entity: " + entity)
+	}
+
+	for from, to := range rules.Rename {
+		if from == "" || to == "" {
+			return errors.New("rename: empty field name")
+		}
+	}
+
+	mapping := dataset.Mapping{Transforms: rules.Transforms}
+	return mapping.Check()
+}
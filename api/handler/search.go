@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"ret/api/models"
+	"ret/pkg/helpers"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search godoc
+// @Summary Search
+// @Description Full-text search of countries, cities and airports. Hits have all the words of q, the last one may be the beginning of a word, and are ranked by where the words occur, the title counting most. The highlight is the text of the hit escaped for HTML, with the matched words in <b> tags.
+// @Tags Search
+// @Accept json
+// @Produce json
+// @Param q query string true "Words to search"
+// @Param type query string false "country | city | airport"
+// @Param country_id query string false "Country ID"
+// @Param limit query int false "Limit, 10 by default and at most 100"
+// @Param offset query int false "Offset"
+// @Success 200 {object} Response{data=models.SearchResponse} "SearchResponseBody"
+// @Failure 400 {object} Response{data=string} "Invalid Argument"
+// @Failure 500 {object} Response{data=string} "Server Error"
+// @Router /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	var req models.SearchRequest
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while binding data: "+err.Error())
+		return
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		handleResponse(c, http.StatusBadRequest, "q is required")
+		return
+	}
+
+	switch req.Type {
+	case "", models.SearchTypeCountry, models.SearchTypeCity, models.SearchTypeAirport:
+	default:
+		handleResponse(c, http.StatusBadRequest, "type must be country, city or airport")
+		return
+	}
+
+	if req.CountryId != "" && !helpers.IsValidUUID(req.CountryId) {
+		handleResponse(c, http.StatusBadRequest, "country_id is not uuid")
+		return
+	}
+
+	resp, err := h.strg.Search().Search(req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Search does not complete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusOK, resp)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"ret/api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Upload godoc
+// @Summary Загрузка таблицы
+// @Description Загрузка строк таблицы из файла. Поддерживаемые таблицы и их поля возвращает GET /upload. В GeoJSON (FeatureCollection) поля берутся из properties объектов, а latitude и longitude из координат точки, id объекта заменяет отсутствующий guid.
+// @Tags Upload
+// @Accept multipart/form-data
+// @Produce json
+// @Param table_slug path string true "country | city | airport | timezone"
+// @Param file formData file true "Файл JSON, NDJSON, GeoJSON, CSV или XLSX, можно сжатый gzip (кроме XLSX)"
+// @Param mode query string false "strict | partial"
+// @Param strategy query string false "insert-only | upsert | skip-existing | replace-all"
+// @Param loader query string false "row | copy (COPY в промежуточную таблицу)"
+// @Param profile query string false "ID профиля сопоставления полей, см. /mapping-profiles"
+// @Param on_missing query string false "reject | null | stub: что делать со ссылками (страна, город, часовой пояс), которые не найдены ни по guid, ни по коду или названию"
+// @Param sheet query string false "Лист книги XLSX, по умолчанию лист с именем таблицы или первый"
+// @Param dry_run query bool false "Только проверить файл, ничего не сохраняя: строки пишутся в транзакции, которая затем откатывается, а заглушки (on_missing=stub) и удаления replace-all только подсчитываются"
+// @Param force query bool false "Импортировать файл заново, даже если он уже был загружен"
+// @Param X-Uploaded-By header string false "Кто загружает файл, сохраняется в истории импорта"
+// @Param Idempotency-Key header string false "Повторный запрос с тем же ключом вернёт ту же задачу"
+// @Success 200 {object} Response{data=models.ImportResult} "Результат проверки (dry_run) или уже созданная задача импорта"
+// @Success 202 {object} Response{data=models.ImportJob} "Задача импорта создана"
+// @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 413 {object} Response{data=models.UploadError} "Файл больше допустимого размера"
+// @Failure 415 {object} Response{data=models.UploadError} "Неподдерживаемый формат файла"
+// @Failure 422 {object} Response{data=string} "Idempotency-Key использован для другого файла"
+// @Failure 404 {object} Response{data=string} "Неизвестная таблица"
+// @Failure 503 {object} Response{data=string} "Очередь импорта переполнена"
+// @Failure 500 {object} Response{data=string} "Ошибка сервера"
+// @Router /upload/{table_slug} [post]
+func (h *Handler) Upload(c *gin.Context) {
+	h.enqueueImport(c, c.Param("table_slug"))
+}
+
+// UploadArchive godoc
+// @Summary Загрузка архива
+// @Description Загрузка ZIP архива с файлами нескольких таблиц одной задачей. Таблицу файла задаёт manifest.json ({"files": [{"name": "countries.csv", "table": "country"}]}) или имя файла (см. file_names в GET /upload). Файлы импортируются в порядке зависимостей: страны, города, аэропорты. Вместо архива можно загрузить книгу XLSX: каждый лист, названный как таблица, импортируется в неё, остальные листы пропускаются.
+// @Tags Upload
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "ZIP архив или книга XLSX"
+// @Param mode query string false "strict | partial"
+// @Param strategy query string false "insert-only | upsert | skip-existing | replace-all"
+// @Param loader query string false "row | copy (COPY в промежуточную таблицу)"
+// @Param profile query string false "ID профиля сопоставления полей, см. /mapping-profiles"
+// @Param on_missing query string false "reject | null | stub: что делать со ссылками (страна, город, часовой пояс), которые не найдены ни по guid, ни по коду или названию"
+// @Param dry_run query bool false "Только проверить файл, ничего не сохраняя: строки пишутся в транзакции, которая затем откатывается, а заглушки (on_missing=stub) и удаления replace-all только подсчитываются"
+// @Param force query bool false "Импортировать файл заново, даже если он уже был загружен"
+// @Param X-Uploaded-By header string false "Кто загружает файл, сохраняется в истории импорта"
+// @Param Idempotency-Key header string false "Повторный запрос с тем же ключом вернёт ту же задачу"
+// @Success 200 {object} Response{data=models.ImportResult} "Результат проверки (dry_run) или уже созданная задача импорта"
+// @Success 202 {object} Response{data=models.ImportJob} "Задача импорта создана"
+// @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 413 {object} Response{data=models.UploadError} "Файл больше допустимого размера"
+// @Failure 415 {object} Response{data=models.UploadError} "Неподдерживаемый формат файла"
+// @Failure 422 {object} Response{data=string} "Idempotency-Key использован для другого файла"
+// @Failure 503 {object} Response{data=string} "Очередь импорта переполнена"
+// @Failure 500 {object} Response{data=string} "Ошибка сервера"
+// @Router /upload/archive [post]
+func (h *Handler) UploadArchive(c *gin.Context) {
+	h.enqueueImport(c, models.ImportEntityArchive)
+}
+
+// UploadSchemas godoc
+// @Summary Таблицы для загрузки
+// @Description Список таблиц, которые принимает /upload/{table_slug}, и полей их файлов
+// @Tags Upload
+// @Produce json
+// @Success 200 {object} Response{data=models.GetListImportSchemaResponse} "Схемы таблиц"
+// @Router /upload [get]
+func (h *Handler) UploadSchemas(c *gin.Context) {
+	schemas := h.strg.Import().Schemas()
+
+	handleResponse(c, http.StatusOK, models.GetListImportSchemaResponse{
+		Count:   len(schemas),
+		Schemas: schemas,
+	})
+}
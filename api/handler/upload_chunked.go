@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"ret/api/models"
+	"ret/config"
+	"ret/pkg/dataset"
+	"ret/pkg/filestore"
+	"ret/pkg/helpers"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+)
+
+// CreateUpload godoc
+// @Summary Начать загрузку по частям
+// @Description Создаёт загрузку большого файла. Файл отправляется частями через PATCH /uploads/{id} и импортируется через POST /uploads/{id}/finalize. После обрыва связи загрузку можно продолжить с offset, который возвращает GET /uploads/{id}.
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param object body models.CreateUpload true "CreateUploadRequestBody"
+// @Param X-Uploaded-By header string false "Кто загружает файл, сохраняется в истории импорта"
+// @Success 201 {object} Response{data=models.Upload} "UploadBody"
+// @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 404 {object} Response{data=string} "Неизвестная таблица"
+// @Failure 413 {object} Response{data=models.UploadError} "Файл больше допустимого размера"
+// @Failure 415 {object} Response{data=models.UploadError} "Неподдерживаемый формат файла"
+// @Failure 500 {object} Response{data=string} "Ошибка сервера"
+// @Router /uploads [post]
+func (h *Handler) CreateUpload(c *gin.Context) {
+	var req models.CreateUpload
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Error while binding data: "+err.Error())
+		return
+	}
+
+	if _, ok := h.strg.Import().Schema(req.Entity); !ok && req.Entity != models.ImportEntityArchive {
+		handleResponse(c, http.StatusNotFound, "Неизвестная таблица: "+req.Entity)
+		return
+	}
+
+	if req.Size <= 0 {
+		handleResponse(c, http.StatusBadRequest, "Укажите размер файла")
+		return
+	}
+
+	if limit := h.cfg.UploadLimit(req.Entity); req.Size > limit {
+		uploadTooLarge(c, limit)
+		return
+	}
+
+	fileName, err := filestore.CleanName(req.FileName)
+	if err != nil {
+		handleResponse(c, http.StatusBadRequest, "Недопустимое имя файла: "+req.FileName)
+		return
+	}
+	req.FileName = fileName
+
+	// The content is checked again on finalize, when it has arrived.
+	var ok bool
+	req.Format, ok = importFormat(c, req.Entity, req.FileName, nil)
+	if !ok {
+		return
+	}
+	req.UploadedBy = c.GetHeader("X-Uploaded-By")
+
+	upload, err := h.strg.Upload().Create(req)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при создании загрузки: "+err.Error())
+		return
+	}
+
+	err = h.files.CreatePart(upload.Guid)
+	if err != nil {
+		h.strg.Upload().Delete(models.UploadPrimaryKey{Id: upload.Guid})
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при создании загрузки: "+err.Error())
+		return
+	}
+
+	c.Header("Location", "/uploads/"+upload.Guid)
+	h.uploadResponse(c, http.StatusCreated, upload)
+}
+
+// UploadGetById godoc
+// @Summary Состояние загрузки по частям
+// @Description Сколько байт файла уже получено. HEAD возвращает то же в заголовках Upload-Offset и Upload-Length.
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 200 {object} Response{data=models.Upload} "UploadBody"
+// @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 404 {object} Response{data=string} "Загрузка не найдена"
+// @Failure 500 {object} Response{data=string} "Ошибка сервера"
+// @Router /uploads/{id} [get]
+// @Router /uploads/{id} [head]
+func (h *Handler) UploadGetById(c *gin.Context) {
+	upload, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	h.uploadResponse(c, http.StatusOK, upload)
+}
+
+// UploadChunk godoc
+// @Summary Загрузка части файла
+// @Description Дописывает тело запроса в файл с позиции Upload-Offset, которая должна совпадать с уже полученным размером. Если связь оборвалась, полученные байты сохраняются.
+// @Tags Upload
+// @Accept application/offset+octet-stream
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Param Upload-Offset header int true "Позиция части в файле"
+// @Success 200 {object} Response{data=models.Upload} "UploadBody"
+// @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 404 {object} Response{data=string} "Загрузка не найдена"
+// @Failure 409 {object} Response{data=string} "Upload-Offset не совпадает или загрузка уже завершена"
+// @Failure 413 {object} Response{data=models.UploadError} "Часть выходит за размер файла"
+// @Failure 500 {object} Response{data=string} "Ошибка сервера"
+// @Router /uploads/{id} [patch]
+func (h *Handler) UploadChunk(c *gin.Context) {
+	upload, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	if upload.Checksum != "" {
+		handleResponse(c, http.StatusConflict, "Загрузка уже завершена")
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		handleResponse(c, http.StatusBadRequest, "Неверный заголовок Upload-Offset")
+		return
+	}
+
+	current, err := h.files.WritePart(upload.Guid, offset, upload.Size, c.Request.Body)
+	switch {
+	case errors.Is(err, filestore.ErrOffsetMismatch):
+		c.Header("Upload-Offset", cast.ToString(current))
+		handleResponse(c, http.StatusConflict, fmt.Sprintf("Upload-Offset не совпадает с загруженным размером %d", current))
+		return
+	case errors.Is(err, filestore.ErrPartBusy):
+		handleResponse(c, http.StatusConflict, "Другая часть этого файла ещё загружается")
+		return
+	case errors.Is(err, filestore.ErrPartTooLarge):
+		uploadTooLarge(c, upload.Size)
+		return
+	case err != nil:
+		handleResponse(c, http.StatusBadRequest, "Ошибка при получении файла: "+err.Error())
+		return
+	}
+
+	h.uploadResponse(c, http.StatusOK, upload)
+}
+
+// UploadFinalize godoc
+// @Summary Импорт загруженного по частям файла
+// @Description Передаёт полностью загруженный файл в импорт. Повторный запрос вернёт уже созданную задачу.
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Param mode query string false "strict | partial"
+// @Param strategy query string false "insert-only | upsert | skip-existing | replace-all"
+// @Param loader query string false "row | copy (COPY в промежуточную таблицу)"
+// @Param profile query string false "ID профиля сопоставления полей, см. /mapping-profiles"
+// @Param on_missing query string false "reject | null | stub: что делать со ссылками (страна, город, часовой пояс), которые не найдены ни по guid, ни по коду или названию"
+// @Param sheet query string false "Лист книги XLSX, по умолчанию лист с именем таблицы или первый"
+// @Param dry_run query bool false "Только проверить файл, ничего не сохраняя: строки пишутся в транзакции, которая затем откатывается, а заглушки (on_missing=stub) и удаления replace-all только подсчитываются"
+// @Param force query bool false "Импортировать файл заново, даже если он уже был загружен"
+// @Param X-Uploaded-By header string false "Кто загружает файл, сохраняется в истории импорта"
+// @Param Idempotency-Key header string false "Повторный запрос с тем же ключом вернёт ту же задачу"
+// @Success 200 {object} Response{data=models.ImportResult} "Результат проверки (dry_run) или уже созданная задача импорта"
+// @Success 202 {object} Response{data=models.ImportJob} "Задача импорта создана"
+// @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 404 {object} Response{data=string} "Загрузка не найдена"
+// @Failure 409 {object} Response{data=string} "Файл загружен не полностью"
+// @Failure 415 {object} Response{data=models.UploadError} "Неподдерживаемый формат файла"
+// @Failure 422 {object} Response{data=string} "Idempotency-Key использован для другого файла"
+// @Failure 503 {object} Response{data=string} "Очередь импорта переполнена"
+// @Failure 500 {object} Response{data=string} "Ошибка сервера"
+// @Router /uploads/{id}/finalize [post]
+func (h *Handler) UploadFinalize(c *gin.Context) {
+	upload, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	req, ok := h.importOptions(c, upload.Entity)
+	if !ok {
+		return
+	}
+	req.FileName = upload.FileName
+	if req.UploadedBy == "" {
+		req.UploadedBy = upload.UploadedBy
+	}
+
+	filePath := h.files.PartPath(upload.Guid)
+	if upload.Checksum == "" {
+		size, err := h.files.PartSize(upload.Guid)
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, "Ошибка при чтении файла: "+err.Error())
+			return
+		}
+
+		if size != upload.Size {
+			handleResponse(c, http.StatusConflict, fmt.Sprintf("Файл загружен не полностью: %d из %d байт", size, upload.Size))
+			return
+		}
+	} else {
+		path, release, err := h.files.Fetch(c.Request.Context(), h.files.Key(upload.Checksum))
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, "Ошибка при чтении файла: "+err.Error())
+			return
+		}
+		defer release()
+		filePath = path
+	}
+
+	head, err := dataset.HeadFile(filePath)
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при чтении файла: "+err.Error())
+		return
+	}
+
+	req.Format, ok = importFormat(c, upload.Entity, upload.FileName, head)
+	if !ok {
+		return
+	}
+
+	// A dry run leaves the part in place, so the upload can still be
+	// finalized for real afterwards.
+	if cast.ToBool(c.Query("dry_run")) {
+		h.dryRunImport(c, req, filePath)
+		return
+	}
+
+	if upload.Checksum != "" {
+		req.FilePath = h.files.Key(upload.Checksum)
+		req.Checksum = upload.Checksum
+		h.submitImport(c, req)
+		return
+	}
+
+	stored, err := h.files.CommitPart(c.Request.Context(), upload.Guid)
+	if errors.Is(err, filestore.ErrPartBusy) {
+		handleResponse(c, http.StatusConflict, "Загрузка уже завершается")
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при сохранении файла: "+err.Error())
+		return
+	}
+
+	req.FilePath = stored.Key
+	req.Checksum = stored.Checksum
+	if !h.submitImport(c, req) {
+		return
+	}
+
+	// The upload is only marked complete once its job exists. Until then the
+	// part is kept and finalize can be retried; a retry after a failure here
+	// finds the job that was created.
+	_, err = h.strg.Upload().Update(models.UpdateUpload{Guid: upload.Guid, Checksum: stored.Checksum})
+	if err != nil {
+		log.Println(config.Error, "upload", upload.Guid, "is not marked complete:", err)
+		return
+	}
+
+	if err := h.files.RemovePart(upload.Guid); err != nil {
+		log.Println(config.Error, "upload", upload.Guid, "part is not removed:", err)
+	}
+}
+
+// UploadDelete godoc
+// @Summary Отменить загрузку по частям
+// @Description Удаляет загрузку и полученные части файла. Файл завершённой загрузки остаётся у задач импорта.
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 204 {string} models.NoContent ""
+// @Failure 400 {object} Response{data=string} "Неверный аргумент"
+// @Failure 404 {object} Response{data=string} "Загрузка не найдена"
+// @Failure 409 {object} Response{data=string} "Часть файла ещё загружается"
+// @Failure 500 {object} Response{data=string} "Ошибка сервера"
+// @Router /uploads/{id} [delete]
+func (h *Handler) UploadDelete(c *gin.Context) {
+	upload, ok := h.getUpload(c)
+	if !ok {
+		return
+	}
+
+	err := h.files.RemovePart(upload.Guid)
+	if errors.Is(err, filestore.ErrPartBusy) {
+		handleResponse(c, http.StatusConflict, "Часть файла ещё загружается")
+		return
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Ошибка при удалении файла: "+err.Error())
+		return
+	}
+
+	err = h.strg.Upload().Delete(models.UploadPrimaryKey{Id: upload.Guid})
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Upload does not delete: "+err.Error())
+		return
+	}
+
+	handleResponse(c, http.StatusNoContent, nil)
+}
+
+// getUpload loads the upload of the :id path parameter. It writes the error
+// response and returns false if there is none.
+func (h *Handler) getUpload(c *gin.Context) (*models.Upload, bool) {
+	var id = c.Param("id")
+	if !helpers.IsValidUUID(id) {
+		handleResponse(c, http.StatusBadRequest, "id is not uuid")
+		return nil, false
+	}
+
+	upload, err := h.strg.Upload().GetById(models.UploadPrimaryKey{Id: id})
+	if err == sql.ErrNoRows {
+		handleResponse(c, http.StatusNotFound, "Загрузка не найдена: "+id)
+		return nil, false
+	}
+	if err != nil {
+		handleResponse(c, http.StatusInternalServerError, "Upload does not exist: "+err.Error())
+		return nil, false
+	}
+
+	return upload, true
+}
+
+// uploadResponse answers with upload and the number of bytes received so
+// far, also in the Upload-Offset and Upload-Length headers.
+func (h *Handler) uploadResponse(c *gin.Context, status int, upload *models.Upload) {
+	upload.Offset = upload.Size
+	if upload.Checksum == "" {
+		size, err := h.files.PartSize(upload.Guid)
+		if err != nil {
+			handleResponse(c, http.StatusInternalServerError, "Ошибка при чтении файла: "+err.Error())
+			return
+		}
+		upload.Offset = size
+	}
+
+	c.Header("Upload-Offset", cast.ToString(upload.Offset))
+	c.Header("Upload-Length", cast.ToString(upload.Size))
+	c.Header("Cache-Control", "no-store")
+	handleResponse(c, status, upload)
+}
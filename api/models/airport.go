@@ -64,13 +64,24 @@ type AirportPrimaryKey struct {
 }
 
 type GetListAirportRequest struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+	Offset          int    `json:"offset" form:"offset"`
+	Limit           int    `json:"limit" form:"limit"`
+	CountryId       string `json:"country_id" form:"country_id"`
+	CityId          string `json:"city_id" form:"city_id"`
+	Code            string `json:"code" form:"code"`
+	ProductCountMin *int   `json:"product_count_min" form:"product_count_min"`
+	ProductCountMax *int   `json:"product_count_max" form:"product_count_max"`
+	Sort            string `json:"sort" form:"sort"`
+	// Cursor is the next_cursor or prev_cursor of another page. The page
+	// next to the row it points at is returned and offset does not count.
+	Cursor string `json:"cursor" form:"cursor"`
 }
 
 type GetListAirportResponse struct {
-	Count    int       `json:"count"`
-	Airports []Airport `json:"airports"`
+	Count      int       `json:"count"`
+	Airports   []Airport `json:"airports"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
 }
 
 
@@ -0,0 +1,18 @@
+package models
+
+// AirportImage is where the uploaded image of an airport and its
+// thumbnails are stored. Key is the blob key prefix, empty if the airport
+// has no uploaded image.
+type AirportImage struct {
+	AirportId   string `json:"airport_id"`
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type UpdateAirportImage struct {
+	AirportId   string `json:"airport_id"`
+	Image       string `json:"image"`
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+}
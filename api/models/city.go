@@ -45,13 +45,23 @@ type CityPrimaryKey struct {
 }
 
 type GetListCityRequest struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+	Offset     int    `json:"offset" form:"offset"`
+	Limit      int    `json:"limit" form:"limit"`
+	CountryId  string `json:"country_id" form:"country_id"`
+	TimezoneId string `json:"timezone_id" form:"timezone_id"`
+	// Title matches the cities whose title starts with it.
+	Title string `json:"title" form:"title"`
+	Sort  string `json:"sort" form:"sort"`
+	// Cursor is the next_cursor or prev_cursor of another page. The page
+	// next to the row it points at is returned and offset does not count.
+	Cursor string `json:"cursor" form:"cursor"`
 }
 
 type GetListCityResponse struct {
-	Count  int    `json:"count"`
-	Cities []City `json:"cities"`
+	Count      int    `json:"count"`
+	Cities     []City `json:"cities"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type File struct {
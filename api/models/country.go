@@ -28,11 +28,19 @@ type CountryPrimaryKey struct {
 }
 
 type GetListCountryRequest struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+	Offset    int    `json:"offset" form:"offset"`
+	Limit     int    `json:"limit" form:"limit"`
+	Continent string `json:"continent" form:"continent"`
+	Code      string `json:"code" form:"code"`
+	Sort      string `json:"sort" form:"sort"`
+	// Cursor is the next_cursor or prev_cursor of another page. The page
+	// next to the row it points at is returned and offset does not count.
+	Cursor string `json:"cursor" form:"cursor"`
 }
 
 type GetListCountryResponse struct {
-	Count     int       `json:"count"`
-	Countries []Country `json:"countries"`
+	Count      int       `json:"count"`
+	Countries  []Country `json:"countries"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
 }
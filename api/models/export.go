@@ -0,0 +1,10 @@
+package models
+
+// ExportRequest selects the rows GET /export/:table_slug writes. The filters
+// and the sort of the list request of the entity apply, the paging does not.
+type ExportRequest struct {
+	Entity  string                `json:"entity"`
+	Country GetListCountryRequest `json:"country"`
+	City    GetListCityRequest    `json:"city"`
+	Airport GetListAirportRequest `json:"airport"`
+}
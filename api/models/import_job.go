@@ -0,0 +1,258 @@
+package models
+
+const (
+	ImportEntityCountry  = "country"
+	ImportEntityCity     = "city"
+	ImportEntityAirport  = "airport"
+	ImportEntityTimezone = "timezone"
+	// ImportEntityArchive is a zip of several entity files imported together.
+	ImportEntityArchive = "archive"
+)
+
+const (
+	ImportJobQueued    = "queued"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+	ImportJobCancelled = "cancelled"
+	// ImportJobRolledBack is a completed job whose rows were rolled back.
+	ImportJobRolledBack = "rolled_back"
+)
+
+// ImportModeStrict rolls the whole file back on the first bad row,
+// ImportModePartial commits the good rows and reports the bad ones.
+const (
+	ImportModeStrict  = "strict"
+	ImportModePartial = "partial"
+)
+
+// Conflict strategies decide what happens to a row whose guid already
+// exists. ImportStrategyReplaceAll also deletes the rows missing from the file.
+const (
+	ImportStrategyInsertOnly   = "insert-only"
+	ImportStrategyUpsert       = "upsert"
+	ImportStrategySkipExisting = "skip-existing"
+	ImportStrategyReplaceAll   = "replace-all"
+)
+
+// ImportLoaderRow writes the file row by row, ImportLoaderCopy bulk loads it
+// with COPY into a staging table and merges it from there.
+const (
+	ImportLoaderRow  = "row"
+	ImportLoaderCopy = "copy"
+)
+
+// What happens to a reference, like the country of a city, that matches no
+// row: ImportOnMissingReject rejects the row, ImportOnMissingNull clears the
+// reference and ImportOnMissingStub creates a stub row from the value. By
+// default city countries and timezones are cleared and the rest rejected.
+const (
+	ImportOnMissingReject = "reject"
+	ImportOnMissingNull   = "null"
+	ImportOnMissingStub   = "stub"
+)
+
+type ImportJob struct {
+	Guid           string `json:"guid"`
+	Entity         string `json:"entity"`
+	FileName       string `json:"file_name"`
+	FilePath       string `json:"file_path"`
+	Checksum       string `json:"checksum"`
+	UploadedBy     string `json:"uploaded_by"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Format         string `json:"format"`
+	Mode           string `json:"mode"`
+	Strategy       string `json:"strategy"`
+	Loader         string `json:"loader"`
+	// MappingProfileId is the mapping profile applied to the file, if any.
+	MappingProfileId string `json:"mapping_profile_id"`
+	OnMissing        string `json:"on_missing"`
+	Sheet            string `json:"sheet"`
+	Status           string `json:"status"`
+	RowsProcessed    int    `json:"rows_processed"`
+	RowsInserted     int    `json:"rows_inserted"`
+	RowsUpdated      int    `json:"rows_updated"`
+	RowsSkipped      int    `json:"rows_skipped"`
+	RowsDeleted      int    `json:"rows_deleted"`
+	RowsFailed       int    `json:"rows_failed"`
+	Error            string `json:"error"`
+	StartedAt        string `json:"started_at"`
+	FinishedAt       string `json:"finished_at"`
+	RolledBackAt     string `json:"rolled_back_at"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+type CreateImportJob struct {
+	Entity           string `json:"entity"`
+	FileName         string `json:"file_name"`
+	FilePath         string `json:"file_path"`
+	Checksum         string `json:"checksum"`
+	UploadedBy       string `json:"uploaded_by"`
+	IdempotencyKey   string `json:"idempotency_key"`
+	Format           string `json:"format"`
+	Mode             string `json:"mode"`
+	Strategy         string `json:"strategy"`
+	Loader           string `json:"loader"`
+	MappingProfileId string `json:"mapping_profile_id"`
+	OnMissing        string `json:"on_missing"`
+	Sheet            string `json:"sheet"`
+}
+
+type UpdateImportJob struct {
+	Guid          string `json:"guid"`
+	Status        string `json:"status"`
+	RowsProcessed int    `json:"rows_processed"`
+	RowsInserted  int    `json:"rows_inserted"`
+	RowsUpdated   int    `json:"rows_updated"`
+	RowsSkipped   int    `json:"rows_skipped"`
+	RowsDeleted   int    `json:"rows_deleted"`
+	RowsFailed    int    `json:"rows_failed"`
+	Error         string `json:"error"`
+}
+
+type ImportJobPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type GetListImportJobRequest struct {
+	Offset int    `json:"offset" form:"offset"`
+	Limit  int    `json:"limit" form:"limit"`
+	Entity string `json:"entity" form:"entity"`
+}
+
+type GetListImportJobResponse struct {
+	Count      int         `json:"count"`
+	ImportJobs []ImportJob `json:"import_jobs"`
+}
+
+// ImportRollback is the outcome of rolling an import batch back.
+type ImportRollback struct {
+	Job          ImportJob `json:"job"`
+	RowsDeleted  int       `json:"rows_deleted"`
+	RowsRestored int       `json:"rows_restored"`
+}
+
+// ImportRequest is passed to the repo importers by the import worker.
+type ImportRequest struct {
+	FilePath     string            `json:"file_path"`
+	Format       string            `json:"format"`
+	Aliases      map[string]string `json:"aliases"`
+	KeepLegacyId bool              `json:"keep_legacy_id"`
+	Mode         string            `json:"mode"`
+	Strategy     string            `json:"strategy"`
+	Loader       string            `json:"loader"`
+	// Mapping reshapes the records of the file before they are bound.
+	Mapping   *MappingRules `json:"mapping"`
+	OnMissing string        `json:"on_missing"`
+	// Sheet is the sheet of an XLSX file to read. By default it is the one
+	// named after the table, or else the first.
+	Sheet string `json:"sheet"`
+	// UnpackLimit bounds the bytes the files of an archive unpack to, 0
+	// means no limit.
+	UnpackLimit int64 `json:"unpack_limit"`
+	// BatchId tags every written row, so the import can be rolled back.
+	BatchId    string             `json:"batch_id"`
+	DryRun     bool               `json:"dry_run"`
+	OnProgress func(ImportResult) `json:"-"`
+}
+
+// Progress reports the running totals of an import, if anybody listens.
+func (r ImportRequest) Progress(result ImportResult) {
+	if r.OnProgress != nil {
+		r.OnProgress(result)
+	}
+}
+
+// ImportMaxErrors bounds the row errors an import keeps, so a bad file does
+// not fill the memory; the rows failing past it are only counted.
+const ImportMaxErrors = 1000
+
+type ImportResult struct {
+	DryRun        bool             `json:"dry_run"`
+	RowsProcessed int              `json:"rows_processed"`
+	RowsInserted  int              `json:"rows_inserted"`
+	RowsUpdated   int              `json:"rows_updated"`
+	RowsSkipped   int              `json:"rows_skipped"`
+	RowsDeleted   int              `json:"rows_deleted"`
+	RowsFailed    int              `json:"rows_failed"`
+	StubsCreated  int              `json:"stubs_created"`
+	Errors        []ImportRowError `json:"errors"`
+	// Files breaks the counts of an archive import down by file.
+	Files []ImportFileResult `json:"files,omitempty"`
+}
+
+// AddError counts a failed row and keeps its error, up to ImportMaxErrors.
+func (r *ImportResult) AddError(rowErr ImportRowError) {
+	r.RowsFailed++
+	if len(r.Errors) < ImportMaxErrors {
+		r.Errors = append(r.Errors, rowErr)
+	}
+}
+
+type ImportFileResult struct {
+	File          string `json:"file"`
+	Entity        string `json:"entity"`
+	RowsProcessed int    `json:"rows_processed"`
+	RowsInserted  int    `json:"rows_inserted"`
+	RowsUpdated   int    `json:"rows_updated"`
+	RowsSkipped   int    `json:"rows_skipped"`
+	RowsDeleted   int    `json:"rows_deleted"`
+	RowsFailed    int    `json:"rows_failed"`
+	StubsCreated  int    `json:"stubs_created"`
+}
+
+// ImportBundleFile is one file of an archive import. Entity may be empty,
+// the file name then decides.
+type ImportBundleFile struct {
+	Name     string `json:"name"`
+	Entity   string `json:"entity"`
+	Format   string `json:"format"`
+	FilePath string `json:"file_path"`
+	Sheet    string `json:"sheet"`
+}
+
+// ImportRowError describes why a single row of an import file was rejected.
+// Index is the zero based position of the row in the file; File names the
+// file within an archive.
+type ImportRowError struct {
+	File   string `json:"file,omitempty"`
+	Index  int    `json:"index"`
+	Guid   string `json:"guid"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+type CreateImportReport struct {
+	JobId  string           `json:"job_id"`
+	Errors []ImportRowError `json:"errors"`
+}
+
+// ImportReport lists the errors of an import job. Count is every rejected
+// row, Errors the first ImportMaxErrors of them.
+type ImportReport struct {
+	Job    ImportJob        `json:"job"`
+	Count  int              `json:"count"`
+	Errors []ImportRowError `json:"errors"`
+}
+
+// ImportSchema describes the file an upload endpoint accepts.
+type ImportSchema struct {
+	Slug  string `json:"slug"`
+	Table string `json:"table"`
+	// FileNames are the names, without extension, files of this entity
+	// have in archives without a manifest.
+	FileNames []string      `json:"file_names"`
+	Fields    []ImportField `json:"fields"`
+}
+
+type ImportField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
+
+type GetListImportSchemaResponse struct {
+	Count   int            `json:"count"`
+	Schemas []ImportSchema `json:"schemas"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// MappingProfile adapts the files of one vendor to the models: it renames,
+// drops, transforms and defaults fields before a record is bound. Entity
+// limits the profile to uploads of one table; an empty Entity fits any
+// upload, archives included.
+type MappingProfile struct {
+	Guid      string       `json:"guid"`
+	Name      string       `json:"name"`
+	Entity    string       `json:"entity"`
+	Rules     MappingRules `json:"rules"`
+	CreatedAt string       `json:"created_at"`
+	UpdatedAt string       `json:"updated_at"`
+}
+
+// MappingRules are applied in the order of the fields. Rename maps source
+// columns to model fields; Drop, Transforms and Defaults use the model
+// field names. Transforms are trim, upper, lower and number; number takes a
+// decimal point or comma and spaces between thousands, "1,234" is 1.234.
+type MappingRules struct {
+	Rename     map[string]string      `json:"rename"`
+	Drop       []string               `json:"drop"`
+	Transforms map[string][]string    `json:"transforms"`
+	Defaults   map[string]interface{} `json:"defaults"`
+}
+
+type CreateMappingProfile struct {
+	Name   string       `json:"name"`
+	Entity string       `json:"entity"`
+	Rules  MappingRules `json:"rules"`
+}
+
+type UpdateMappingProfile struct {
+	Guid   string       `json:"guid"`
+	Name   string       `json:"name"`
+	Entity string       `json:"entity"`
+	Rules  MappingRules `json:"rules"`
+}
+
+type MappingProfilePrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type GetListMappingProfileRequest struct {
+	Offset int    `json:"offset" form:"offset"`
+	Limit  int    `json:"limit" form:"limit"`
+	Entity string `json:"entity" form:"entity"`
+}
+
+type GetListMappingProfileResponse struct {
+	Count           int              `json:"count"`
+	MappingProfiles []MappingProfile `json:"mapping_profiles"`
+}
@@ -0,0 +1,33 @@
+package models
+
+const (
+	SearchTypeCountry = "country"
+	SearchTypeCity    = "city"
+	SearchTypeAirport = "airport"
+)
+
+type SearchRequest struct {
+	Offset int    `json:"offset" form:"offset"`
+	Limit  int    `json:"limit" form:"limit"`
+	Query  string `json:"q" form:"q"`
+	// Type restricts the hits to country, city or airport.
+	Type      string `json:"type" form:"type"`
+	CountryId string `json:"country_id" form:"country_id"`
+}
+
+// SearchHit is a country, city or airport matching a search. Highlight is
+// its text escaped for HTML, with the matched words in <b> tags.
+type SearchHit struct {
+	Type      string  `json:"type"`
+	Guid      string  `json:"guid"`
+	Title     string  `json:"title"`
+	Code      string  `json:"code"`
+	CountryId string  `json:"country_id"`
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+}
+
+type SearchResponse struct {
+	Count int         `json:"count"`
+	Hits  []SearchHit `json:"hits"`
+}
@@ -0,0 +1,8 @@
+package models
+
+type Timezone struct {
+	Guid      string `json:"guid"`
+	Title     string `json:"title"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// Upload is a file sent in chunks. Offset is how many bytes have arrived;
+// once it reaches Size the upload can be finalized into an import job.
+type Upload struct {
+	Guid       string `json:"guid"`
+	Entity     string `json:"entity"`
+	FileName   string `json:"file_name"`
+	Format     string `json:"format"`
+	Size       int64  `json:"size"`
+	Offset     int64  `json:"offset"`
+	Checksum   string `json:"checksum"`
+	UploadedBy string `json:"uploaded_by"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type UploadPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateUpload struct {
+	Entity     string `json:"entity"`
+	FileName   string `json:"file_name"`
+	Format     string `json:"-"`
+	Size       int64  `json:"size"`
+	UploadedBy string `json:"-"`
+}
+
+type UpdateUpload struct {
+	Guid     string `json:"guid"`
+	Checksum string `json:"checksum"`
+}
+
+// Codes of UploadError.
+const (
+	UploadErrorTooLarge    = "file_too_large"
+	UploadErrorUnsupported = "unsupported_media_type"
+)
+
+// UploadError is the data of a 413 or 415 response, so clients can tell
+// why a file was refused without parsing the message.
+type UploadError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Limit   int64  `json:"limit,omitempty"`
+	Format  string `json:"format,omitempty"`
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
+	"log"
 	"ret/api"
 	"ret/config"
+	"ret/pkg/blob"
+	"ret/pkg/filestore"
 	"ret/storage/postgres"
-	"log"
+	"ret/worker"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,13 +22,32 @@ func main() {
 		panic(err)
 	}
 
+	blobs, err := blob.New(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	files := filestore.New(cfg.UploadDir, blobs)
+
+	importPool := worker.NewPool(&cfg, pgStorage, files)
+	importPool.Start(context.Background())
+
+	if cfg.InboxDir != "" {
+		inbox := worker.NewInbox(&cfg, pgStorage, importPool, files)
+		go func() {
+			if err := inbox.Run(context.Background()); err != nil {
+				log.Println(config.Error, "inbox:", err)
+			}
+		}()
+		log.Println(config.Info, "Watching inbox:", cfg.InboxDir)
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.New()
 
 	r.Use(gin.Logger(), gin.Recovery())
 
-	api.SetUpApi(r, &cfg, pgStorage)
+	api.SetUpApi(r, &cfg, pgStorage, importPool, files)
 
 	log.Println("Listening:", cfg.ServiceHost+cfg.ServiceHTTPPort, "...")
 	if err := r.Run(cfg.ServiceHost + cfg.ServiceHTTPPort); err != nil {
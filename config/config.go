@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
@@ -23,6 +24,56 @@ type Config struct {
 
 	ServiceHost     string
 	ServiceHTTPPort string
+
+	// UploadDir is where uploaded files are stored by the local blob
+	// backend and where chunked uploads are assembled with any backend.
+	UploadDir string
+
+	// BlobBackend is "local" or "s3". The S3 settings also fit compatible
+	// services like MinIO, which need S3PathStyle.
+	BlobBackend string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool
+
+	// UploadMaxSize is the size limit of an uploaded file in bytes,
+	// UploadMaxSizes overrides it per entity, UPLOAD_MAX_SIZES takes a
+	// JSON object like {"airport": 1073741824}.
+	UploadMaxSize  int64
+	UploadMaxSizes map[string]int64
+
+	// ImageMaxSize is the size limit of an uploaded airport image in bytes.
+	ImageMaxSize int64
+
+	ImportWorkerCount int
+	ImportQueueSize   int
+
+	// ImportColumnAliases maps source column names to model fields,
+	// IMPORT_COLUMN_ALIASES takes a JSON object.
+	ImportColumnAliases map[string]string
+
+	// ImportKeepLegacyId stores the Mongo _id of Extended JSON imports
+	// in legacy_id.
+	ImportKeepLegacyId bool
+
+	// InboxDir is watched for dataset files to import, empty turns the
+	// watcher off. Files are imported with the InboxMode, InboxStrategy
+	// and InboxLoader options and moved to processed/ or failed/ inside
+	// it afterwards.
+	InboxDir          string
+	InboxPollInterval time.Duration
+	InboxMode         string
+	InboxStrategy     string
+	InboxLoader       string
+	// InboxMappingProfile is the id of a mapping profile applied to the
+	// inbox files it fits, if any.
+	InboxMappingProfile string
+	// InboxOnMissing is what happens to references matching no row,
+	// see models.ImportOnMissingReject; empty keeps the defaults.
+	InboxOnMissing string
 }
 
 func Load() Config {
@@ -42,9 +93,58 @@ func Load() Config {
 	cfg.PostgresPassword = cast.ToString(getValueOrDefault("POSTGRES_PASSWORD", "12345"))
 	cfg.PostgresPort = cast.ToString(getValueOrDefault("POSTGRES_PORT", "5432"))
 
+	cfg.UploadDir = cast.ToString(getValueOrDefault("UPLOAD_DIR", "uploads"))
+	cfg.BlobBackend = cast.ToString(getValueOrDefault("BLOB_BACKEND", "local"))
+	cfg.S3Endpoint = cast.ToString(getValueOrDefault("S3_ENDPOINT", "http://localhost:9000"))
+	cfg.S3Region = cast.ToString(getValueOrDefault("S3_REGION", "us-east-1"))
+	cfg.S3Bucket = cast.ToString(getValueOrDefault("S3_BUCKET", "uploads"))
+	cfg.S3AccessKey = cast.ToString(getValueOrDefault("S3_ACCESS_KEY", ""))
+	cfg.S3SecretKey = cast.ToString(getValueOrDefault("S3_SECRET_KEY", ""))
+	cfg.S3PathStyle = cast.ToBool(getValueOrDefault("S3_PATH_STYLE", true))
+
+	cfg.UploadMaxSize = cast.ToInt64(getValueOrDefault("UPLOAD_MAX_SIZE", 128<<20))
+	cfg.UploadMaxSizes = cast.ToStringMapInt64(getValueOrDefault("UPLOAD_MAX_SIZES", map[string]int64{
+		"country":  16 << 20,
+		"timezone": 16 << 20,
+		"airport":  1 << 30,
+		"archive":  1 << 30,
+	}))
+
+	cfg.ImageMaxSize = cast.ToInt64(getValueOrDefault("IMAGE_MAX_SIZE", 10<<20))
+
+	cfg.ImportWorkerCount = cast.ToInt(getValueOrDefault("IMPORT_WORKER_COUNT", 4))
+	cfg.ImportQueueSize = cast.ToInt(getValueOrDefault("IMPORT_QUEUE_SIZE", 100))
+	cfg.ImportColumnAliases = cast.ToStringMapString(getValueOrDefault("IMPORT_COLUMN_ALIASES", map[string]string{
+		"address":   "adress",
+		"lat":       "latitude",
+		"lng":       "longitude",
+		"lon":       "longitude",
+		"countryid": "country_id",
+		"cityid":    "city_id",
+	}))
+	cfg.ImportKeepLegacyId = cast.ToBool(getValueOrDefault("IMPORT_KEEP_LEGACY_ID", true))
+
+	cfg.InboxDir = cast.ToString(getValueOrDefault("INBOX_DIR", ""))
+	cfg.InboxPollInterval = cast.ToDuration(getValueOrDefault("INBOX_POLL_INTERVAL", "10s"))
+	cfg.InboxMode = cast.ToString(getValueOrDefault("INBOX_MODE", "partial"))
+	cfg.InboxStrategy = cast.ToString(getValueOrDefault("INBOX_STRATEGY", "upsert"))
+	cfg.InboxLoader = cast.ToString(getValueOrDefault("INBOX_LOADER", "row"))
+	cfg.InboxMappingProfile = cast.ToString(getValueOrDefault("INBOX_MAPPING_PROFILE", ""))
+	cfg.InboxOnMissing = cast.ToString(getValueOrDefault("INBOX_ON_MISSING", ""))
+
 	return cfg
 }
 
+// UploadLimit is the size limit of files uploaded to entity. It also bounds
+// what an archive may unpack to.
+func (cfg *Config) UploadLimit(entity string) int64 {
+	if limit, ok := cfg.UploadMaxSizes[entity]; ok && limit > 0 {
+		return limit
+	}
+
+	return cfg.UploadMaxSize
+}
+
 func getValueOrDefault(key string, defaultValue interface{}) interface{} {
 
 	val, exists := os.LookupEnv(key)
@@ -0,0 +1,15 @@
+
+CREATE TABLE import_jobs (
+    guid UUID PRIMARY KEY,
+    entity VARCHAR(32) NOT NULL,
+    file_name VARCHAR(255),
+    file_path VARCHAR(255),
+    status VARCHAR(16) NOT NULL DEFAULT 'queued',
+    rows_processed INT NOT NULL DEFAULT 0,
+    rows_failed INT NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
@@ -0,0 +1,4 @@
+
+DROP TABLE import_job_errors;
+
+ALTER TABLE import_jobs DROP COLUMN mode;
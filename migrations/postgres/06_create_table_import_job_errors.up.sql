@@ -0,0 +1,13 @@
+
+ALTER TABLE import_jobs ADD COLUMN mode VARCHAR(16) NOT NULL DEFAULT 'strict';
+
+CREATE TABLE import_job_errors (
+    job_id UUID REFERENCES import_jobs(guid) ON DELETE CASCADE,
+    row_index INT NOT NULL,
+    guid VARCHAR(36),
+    field VARCHAR(64),
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX import_job_errors_job_id_idx ON import_job_errors(job_id, row_index);
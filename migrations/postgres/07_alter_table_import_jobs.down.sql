@@ -0,0 +1,6 @@
+
+ALTER TABLE import_jobs DROP COLUMN strategy;
+ALTER TABLE import_jobs DROP COLUMN rows_inserted;
+ALTER TABLE import_jobs DROP COLUMN rows_updated;
+ALTER TABLE import_jobs DROP COLUMN rows_skipped;
+ALTER TABLE import_jobs DROP COLUMN rows_deleted;
@@ -0,0 +1,6 @@
+
+ALTER TABLE import_jobs ADD COLUMN strategy VARCHAR(16) NOT NULL DEFAULT 'insert-only';
+ALTER TABLE import_jobs ADD COLUMN rows_inserted INT NOT NULL DEFAULT 0;
+ALTER TABLE import_jobs ADD COLUMN rows_updated INT NOT NULL DEFAULT 0;
+ALTER TABLE import_jobs ADD COLUMN rows_skipped INT NOT NULL DEFAULT 0;
+ALTER TABLE import_jobs ADD COLUMN rows_deleted INT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+
+ALTER TABLE import_jobs ADD COLUMN loader VARCHAR(16) NOT NULL DEFAULT 'row';
@@ -0,0 +1,4 @@
+
+ALTER TABLE buildings DROP COLUMN legacy_id;
+ALTER TABLE cities DROP COLUMN legacy_id;
+ALTER TABLE countries DROP COLUMN legacy_id;
@@ -0,0 +1,4 @@
+
+ALTER TABLE countries ADD COLUMN legacy_id VARCHAR(24);
+ALTER TABLE cities ADD COLUMN legacy_id VARCHAR(24);
+ALTER TABLE buildings ADD COLUMN legacy_id VARCHAR(24);
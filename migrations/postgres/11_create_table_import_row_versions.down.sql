@@ -0,0 +1,11 @@
+
+DROP TABLE import_row_versions;
+
+ALTER TABLE timezone DROP COLUMN import_batch_id;
+ALTER TABLE buildings DROP COLUMN import_batch_id;
+ALTER TABLE cities DROP COLUMN import_batch_id;
+ALTER TABLE countries DROP COLUMN import_batch_id;
+
+ALTER TABLE import_jobs DROP COLUMN rolled_back_at;
+ALTER TABLE import_jobs DROP COLUMN uploaded_by;
+ALTER TABLE import_jobs DROP COLUMN checksum;
@@ -0,0 +1,21 @@
+
+ALTER TABLE import_jobs ADD COLUMN checksum VARCHAR(64);
+ALTER TABLE import_jobs ADD COLUMN uploaded_by VARCHAR(255);
+ALTER TABLE import_jobs ADD COLUMN rolled_back_at TIMESTAMP;
+
+ALTER TABLE countries ADD COLUMN import_batch_id UUID;
+ALTER TABLE cities ADD COLUMN import_batch_id UUID;
+ALTER TABLE buildings ADD COLUMN import_batch_id UUID;
+ALTER TABLE timezone ADD COLUMN import_batch_id UUID;
+
+-- Before-images of the rows an import batch wrote or deleted. The batch id
+-- is the guid of the import job.
+CREATE TABLE import_row_versions (
+    batch_id UUID NOT NULL,
+    table_name VARCHAR(64) NOT NULL,
+    guid VARCHAR(36) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    before JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (batch_id, table_name, guid)
+);
@@ -0,0 +1,5 @@
+
+DROP INDEX import_jobs_checksum_idx;
+DROP INDEX import_jobs_idempotency_key_idx;
+
+ALTER TABLE import_jobs DROP COLUMN idempotency_key;
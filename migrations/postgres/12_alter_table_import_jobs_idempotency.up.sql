@@ -0,0 +1,5 @@
+
+ALTER TABLE import_jobs ADD COLUMN idempotency_key VARCHAR(255);
+
+CREATE UNIQUE INDEX import_jobs_idempotency_key_idx ON import_jobs(idempotency_key);
+CREATE INDEX import_jobs_checksum_idx ON import_jobs(entity, checksum);
@@ -0,0 +1,2 @@
+
+ALTER TABLE import_job_errors DROP COLUMN file;
@@ -0,0 +1,14 @@
+
+-- Files sent in chunks through /uploads. The bytes are assembled on disk,
+-- checksum is set once the upload is finalized.
+CREATE TABLE uploads (
+    guid UUID PRIMARY KEY,
+    entity VARCHAR(32) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    format VARCHAR(16) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64),
+    uploaded_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
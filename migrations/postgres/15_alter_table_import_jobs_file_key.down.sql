@@ -0,0 +1,8 @@
+
+-- file_path goes back to the local path of the file, which is under
+-- UPLOAD_DIR. SQL cannot read the service config, so set app.upload_dir to
+-- it when UPLOAD_DIR is not the default "uploads", e.g. with
+-- PGOPTIONS="-c app.upload_dir=/data/uploads".
+UPDATE import_jobs
+SET file_path = COALESCE(NULLIF(current_setting('app.upload_dir', true), ''), 'uploads') || '/sha256/' || checksum
+WHERE checksum IS NOT NULL;
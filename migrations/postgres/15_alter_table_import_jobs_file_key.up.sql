@@ -0,0 +1,3 @@
+
+-- file_path now holds the blob key of the file instead of a local path.
+UPDATE import_jobs SET file_path = 'sha256/' || checksum WHERE checksum IS NOT NULL;
@@ -0,0 +1,4 @@
+
+ALTER TABLE buildings DROP COLUMN image_updated_at;
+ALTER TABLE buildings DROP COLUMN image_type;
+ALTER TABLE buildings DROP COLUMN image_key;
@@ -0,0 +1,6 @@
+
+-- image_key is the blob key prefix of the uploaded image and its
+-- thumbnails, image keeps the URL they are served from.
+ALTER TABLE buildings ADD COLUMN image_key VARCHAR(255);
+ALTER TABLE buildings ADD COLUMN image_type VARCHAR(32);
+ALTER TABLE buildings ADD COLUMN image_updated_at TIMESTAMP;
@@ -0,0 +1,4 @@
+
+ALTER TABLE import_jobs DROP COLUMN mapping_profile_id;
+
+DROP TABLE mapping_profiles;
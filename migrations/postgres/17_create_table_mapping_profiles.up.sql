@@ -0,0 +1,15 @@
+
+-- Mapping profiles adapt the files of one vendor to the models, see
+-- models.MappingRules. entity is empty for profiles that fit any upload.
+CREATE TABLE mapping_profiles (
+    guid UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    entity VARCHAR(32),
+    rules JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+-- Not a foreign key: deleting a profile keeps the history of the jobs that
+-- used it, and a queued job fails instead of importing without it.
+ALTER TABLE import_jobs ADD COLUMN mapping_profile_id UUID;
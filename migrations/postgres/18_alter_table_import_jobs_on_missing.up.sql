@@ -0,0 +1,4 @@
+
+-- What happens to references of the file that match no row: reject, null
+-- or stub. NULL keeps the defaults of each reference.
+ALTER TABLE import_jobs ADD COLUMN on_missing VARCHAR(16);
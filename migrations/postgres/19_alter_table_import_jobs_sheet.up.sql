@@ -0,0 +1,4 @@
+
+-- The sheet of an XLSX file to import. NULL picks the sheet named after the
+-- table, or else the first one.
+ALTER TABLE import_jobs ADD COLUMN sheet VARCHAR(255);
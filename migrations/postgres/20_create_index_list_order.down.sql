@@ -0,0 +1,4 @@
+
+DROP INDEX buildings_created_at_idx;
+DROP INDEX cities_created_at_idx;
+DROP INDEX countries_created_at_idx;
@@ -0,0 +1,6 @@
+
+-- Lists are ordered by created_at and guid unless sorted otherwise, cursor
+-- pages seek along these indexes instead of skipping rows.
+CREATE INDEX countries_created_at_idx ON countries(created_at, guid);
+CREATE INDEX cities_created_at_idx ON cities(created_at, guid);
+CREATE INDEX buildings_created_at_idx ON buildings(created_at, guid);
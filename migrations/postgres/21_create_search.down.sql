@@ -0,0 +1,12 @@
+
+DROP TRIGGER buildings_search_vector ON buildings;
+DROP TRIGGER cities_search_vector ON cities;
+DROP TRIGGER countries_search_vector ON countries;
+
+DROP FUNCTION buildings_search_vector();
+DROP FUNCTION cities_search_vector();
+DROP FUNCTION countries_search_vector();
+
+ALTER TABLE buildings DROP COLUMN search_vector;
+ALTER TABLE cities DROP COLUMN search_vector;
+ALTER TABLE countries DROP COLUMN search_vector;
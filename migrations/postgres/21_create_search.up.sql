@@ -0,0 +1,55 @@
+
+-- Full-text search of GET /search. Every table keeps a weighted tsvector of
+-- its text, built by a trigger so the imports and COPY keep it up to date
+-- too: the title weighs most, then the code, then the rest. The simple
+-- configuration does not stem, as names are in many languages.
+ALTER TABLE countries ADD COLUMN search_vector tsvector;
+ALTER TABLE cities ADD COLUMN search_vector tsvector;
+ALTER TABLE buildings ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION countries_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(NEW.continent, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION cities_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.city_code, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(NEW.country_name, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION buildings_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.code, '')), 'B') ||
+        setweight(to_tsvector('simple', concat_ws(' ', NEW.city, NEW.country)), 'C') ||
+        setweight(to_tsvector('simple', concat_ws(' ', NEW.address, NEW.search_text)), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER countries_search_vector BEFORE INSERT OR UPDATE ON countries
+    FOR EACH ROW EXECUTE FUNCTION countries_search_vector();
+CREATE TRIGGER cities_search_vector BEFORE INSERT OR UPDATE ON cities
+    FOR EACH ROW EXECUTE FUNCTION cities_search_vector();
+CREATE TRIGGER buildings_search_vector BEFORE INSERT OR UPDATE ON buildings
+    FOR EACH ROW EXECUTE FUNCTION buildings_search_vector();
+
+-- The triggers fill in the rows already there.
+UPDATE countries SET search_vector = NULL;
+UPDATE cities SET search_vector = NULL;
+UPDATE buildings SET search_vector = NULL;
+
+CREATE INDEX countries_search_vector_idx ON countries USING GIN (search_vector);
+CREATE INDEX cities_search_vector_idx ON cities USING GIN (search_vector);
+CREATE INDEX buildings_search_vector_idx ON buildings USING GIN (search_vector);
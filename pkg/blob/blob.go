@@ -0,0 +1,63 @@
+// Package blob stores files by key, either in a local directory or in an
+// S3-compatible bucket. Keys are slash separated paths like
+// "sha256/<checksum>".
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"ret/config"
+	"strings"
+)
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotFound means there is no blob with the key.
+var ErrNotFound = errors.New("blob not found")
+
+// Store is where uploaded files end up.
+type Store interface {
+	// PutFile stores the local file at path under key. The file is consumed:
+	// it may be moved and must not be used by the caller afterwards.
+	PutFile(ctx context.Context, key, path string) error
+	// Get opens the blob with key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete removes the blob with key. A missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New opens the backend chosen by cfg.BlobBackend.
+func New(cfg *config.Config) (Store, error) {
+	switch cfg.BlobBackend {
+	case BackendLocal, "":
+		return NewLocal(cfg.UploadDir), nil
+	case BackendS3:
+		return NewS3(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	}
+
+	return nil, fmt.Errorf("unknown blob backend: %s", cfg.BlobBackend)
+}
+
+// checkKey rejects keys that are not clean relative paths, so a key can
+// never point outside the store.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+
+	return nil
+}
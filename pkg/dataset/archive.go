@@ -0,0 +1,184 @@
+package dataset
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestName is the optional file at the root of an archive that says
+// which table every data file belongs to.
+const ManifestName = "manifest.json"
+
+// ErrArchiveTooLarge means the files of an archive unpack to more bytes than
+// Extract may write.
+var ErrArchiveTooLarge = errors.New("archive unpacks to more than the size limit")
+
+// Manifest is the content of manifest.json, e.g.
+//
+//	{"files": [{"name": "data/countries.csv", "table": "country"}]}
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Table  string `json:"table"`
+	Format string `json:"format"`
+}
+
+// ArchiveFile is a data file extracted from an archive. Table is empty if
+// the archive has no manifest, the caller then goes by Name.
+type ArchiveFile struct {
+	Name   string
+	Table  string
+	Format string
+	Path   string
+}
+
+// Extract unpacks the data files of the zip archive at src into dir. With a
+// manifest only the listed files are extracted, otherwise every file of a
+// known format. Together they may not unpack to more than limit bytes, a
+// small archive of highly compressed data could fill the disk otherwise;
+// limit 0 means no limit.
+func Extract(src, dir string, limit int64) ([]ArchiveFile, error) {
+	archive, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, entry := range archive.File {
+		entries[path.Clean(entry.Name)] = entry
+	}
+
+	var files []ArchiveFile
+	if entry, ok := entries[ManifestName]; ok {
+		var manifest Manifest
+		if err := readJSON(entry, &manifest); err != nil {
+			return nil, fmt.Errorf("%s: %w", ManifestName, err)
+		}
+
+		for _, file := range manifest.Files {
+			if _, ok := entries[path.Clean(file.Name)]; !ok {
+				return nil, fmt.Errorf("%s: %s is not in the archive", ManifestName, file.Name)
+			}
+			if file.Format == "" {
+				file.Format = FormatFromFile(file.Name, "")
+			}
+			files = append(files, ArchiveFile{Name: path.Clean(file.Name), Table: file.Table, Format: file.Format})
+		}
+	} else {
+		for _, entry := range archive.File {
+			name := path.Clean(entry.Name)
+			if entry.FileInfo().IsDir() || isHidden(name) {
+				continue
+			}
+
+			format := FormatFromFile(name, "")
+			if format == "" || format == FormatZip {
+				continue
+			}
+			files = append(files, ArchiveFile{Name: name, Format: format})
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("archive has no data files")
+	}
+
+	left := limit
+	for i := range files {
+		if files[i].Format == "" || files[i].Format == FormatZip {
+			return nil, fmt.Errorf("%s: unknown dataset format", files[i].Name)
+		}
+
+		// Entry names are never used as paths, so "../" cannot escape dir.
+		files[i].Path = filepath.Join(dir, fmt.Sprintf("%d-%s", i, path.Base(files[i].Name)))
+		written, err := extractFile(entries[files[i].Name], files[i].Path, limit, left)
+		if errors.Is(err, ErrArchiveTooLarge) {
+			return nil, fmt.Errorf("%w of %d bytes", err, limit)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", files[i].Name, err)
+		}
+		left -= written
+
+		sniffed, err := SniffFile(files[i].Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", files[i].Name, err)
+		}
+		if files[i].Format, err = confirm(files[i].Format, sniffed); err != nil {
+			return nil, fmt.Errorf("%s: %w", files[i].Name, err)
+		}
+		if files[i].Format == FormatZip {
+			return nil, fmt.Errorf("%s: archives inside archives are not supported", files[i].Name)
+		}
+	}
+
+	return files, nil
+}
+
+// extractFile unpacks entry to dst. With a limit, at most left bytes may be
+// written; the size the entry claims is checked first, but it is not
+// trusted.
+func extractFile(entry *zip.File, dst string, limit, left int64) (int64, error) {
+	if limit > 0 && entry.UncompressedSize64 > uint64(left) {
+		return 0, ErrArchiveTooLarge
+	}
+
+	src, err := entry.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	var r io.Reader = src
+	if limit > 0 {
+		r = io.LimitReader(src, left+1)
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := io.Copy(file, r)
+	if err == nil && limit > 0 && written > left {
+		err = ErrArchiveTooLarge
+	}
+	if err != nil {
+		file.Close()
+		return written, err
+	}
+
+	return written, file.Close()
+}
+
+func readJSON(entry *zip.File, v interface{}) error {
+	src, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return json.NewDecoder(src).Decode(v)
+}
+
+// isHidden skips the metadata archivers add, like __MACOSX/ and .DS_Store.
+func isHidden(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasPrefix(part, "__") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package dataset
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// FieldError means a record value does not fit the model field.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// Bind copies the record into the struct pointed to by v, matching record
+// keys against json tags. Values are converted to the field type, so CSV
+// strings fill numeric fields and JSON numbers fill string fields. Empty
+// strings leave numeric fields at zero.
+func Bind(record Record, v interface{}) error {
+	rv := reflect.ValueOf(v).Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		name := strings.Split(rt.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		value, ok := record[name]
+		if !ok || value == nil {
+			continue
+		}
+
+		field := rv.Field(i)
+		if s, isString := value.(string); isString && s == "" && field.Kind() != reflect.String {
+			continue
+		}
+
+		var err error
+		switch field.Kind() {
+		case reflect.String:
+			var s string
+			s, err = cast.ToStringE(value)
+			field.SetString(s)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var n int64
+			n, err = cast.ToInt64E(value)
+			field.SetInt(n)
+		case reflect.Float32, reflect.Float64:
+			var f float64
+			f, err = cast.ToFloat64E(value)
+			field.SetFloat(f)
+		case reflect.Bool:
+			var b bool
+			b, err = cast.ToBoolE(value)
+			field.SetBool(b)
+		default:
+			continue
+		}
+		if err != nil {
+			return &FieldError{Field: name, Reason: "unexpected value " + cast.ToString(value)}
+		}
+	}
+
+	return nil
+}
+
+// Field is a record key Bind fills, with the JSON type it expects.
+type Field struct {
+	Name string
+	Type string
+}
+
+// Fields lists the record keys Bind reads into the struct v.
+func Fields(v interface{}) []Field {
+	rt := reflect.TypeOf(v)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	var fields []Field
+	for i := 0; i < rt.NumField(); i++ {
+		name := strings.Split(rt.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		var typ string
+		switch rt.Field(i).Type.Kind() {
+		case reflect.String:
+			typ = "string"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			typ = "integer"
+		case reflect.Float32, reflect.Float64:
+			typ = "number"
+		case reflect.Bool:
+			typ = "boolean"
+		default:
+			continue
+		}
+
+		fields = append(fields, Field{Name: name, Type: typ})
+	}
+
+	return fields
+}
@@ -0,0 +1,52 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+const utf8BOM = "\ufeff"
+
+// csvRows reads a CSV file with a header row. Header names are trimmed and
+// lower-cased, a UTF-8 byte order mark in front of the header is dropped.
+// Short rows simply miss the trailing columns.
+func csvRows(file io.Reader) (func() (Record, error), error) {
+	r := csv.NewReader(bufio.NewReader(file))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return func() (Record, error) { return nil, io.EOF }, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header = append([]string(nil), header...)
+	for i, column := range header {
+		if i == 0 {
+			column = strings.TrimPrefix(column, utf8BOM)
+		}
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+
+	return func() (Record, error) {
+		row, err := r.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		record := make(Record, len(header))
+		for i, value := range row {
+			if i < len(header) && header[i] != "" {
+				record[header[i]] = value
+			}
+		}
+
+		return record, nil
+	}, nil
+}
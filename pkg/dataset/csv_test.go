@@ -0,0 +1,173 @@
+package dataset
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readCSV writes content to a CSV file and reads every record of it.
+func readCSV(tb testing.TB, content string, opts Options) ([]Record, error) {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "cities.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+
+	reader, err := Open(path, FormatCSV, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var records []Record
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		opts    Options
+		want    []Record
+	}{
+		{
+			name:    "byte order mark",
+			content: utf8BOM + "guid,title\n1,Tashkent\n",
+			want:    []Record{{"guid": "1", "title": "Tashkent"}},
+		},
+		{
+			name:    "header is trimmed and lower-cased",
+			content: " GUID ,City Code\r\n1,TAS\r\n",
+			want:    []Record{{"guid": "1", "city code": "TAS"}},
+		},
+		{
+			name:    "quoted commas, quotes and newlines",
+			content: "guid,title,address\n1,\"Tashkent, \"\"Yunusobod\"\"\",\"Amir Temur 1\nfloor 2\"\n",
+			want:    []Record{{"guid": "1", "title": `Tashkent, "Yunusobod"`, "address": "Amir Temur 1\nfloor 2"}},
+		},
+		{
+			name:    "short rows miss the trailing columns, extra ones are dropped",
+			content: "guid,title,city_code\n1,Tashkent\n2,Samarkand,SKD,extra\n",
+			want: []Record{
+				{"guid": "1", "title": "Tashkent"},
+				{"guid": "2", "title": "Samarkand", "city_code": "SKD"},
+			},
+		},
+		{
+			name:    "columns without a name are dropped",
+			content: "guid,,title\n1,x,Tashkent\n",
+			want:    []Record{{"guid": "1", "title": "Tashkent"}},
+		},
+		{
+			name:    "aliases, a column of the same name wins",
+			content: "guid,lat,lng,latitude\n1,41.3,69.2,\n",
+			opts:    Options{Aliases: map[string]string{"lat": "latitude", "lng": "longitude"}},
+			want:    []Record{{"guid": "1", "latitude": "", "longitude": "69.2"}},
+		},
+		{
+			name:    "header only",
+			content: "guid,title\n",
+		},
+		{
+			name: "empty file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := readCSV(t, test.content, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("records are\n%#v\nwant\n%#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadCSVBadQuote(t *testing.T) {
+	_, err := readCSV(t, "guid,title\n1,\"Tashkent\n", Options{})
+	if err == nil {
+		t.Error("an unterminated quote did not fail")
+	}
+}
+
+func TestBindCSV(t *testing.T) {
+	type city struct {
+		Guid      string  `json:"guid"`
+		Title     string  `json:"title"`
+		Latitude  float64 `json:"latitude"`
+		Offset    int     `json:"offset"`
+		IsCapital bool    `json:"is_capital"`
+		Ignored   string  `json:"-"`
+	}
+
+	tests := []struct {
+		name   string
+		record Record
+		want   city
+		field  string
+	}{
+		{
+			name:   "strings fill numbers and booleans",
+			record: Record{"guid": "1", "title": "Tashkent", "latitude": "41.2995", "offset": "5", "is_capital": "true"},
+			want:   city{Guid: "1", Title: "Tashkent", Latitude: 41.2995, Offset: 5, IsCapital: true},
+		},
+		{
+			name:   "empty strings leave numbers at zero",
+			record: Record{"guid": "1", "title": "", "latitude": "", "offset": ""},
+			want:   city{Guid: "1"},
+		},
+		{
+			name:   "unknown columns are ignored",
+			record: Record{"guid": "1", "population": "2956384", "-": "x"},
+			want:   city{Guid: "1"},
+		},
+		{
+			name:   "not a number",
+			record: Record{"guid": "1", "latitude": "north"},
+			field:  "latitude",
+		},
+		{
+			name:   "not an integer",
+			record: Record{"guid": "1", "offset": "5.5"},
+			field:  "offset",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got city
+			err := Bind(test.record, &got)
+
+			if test.field != "" {
+				fieldErr, ok := err.(*FieldError)
+				if !ok || fieldErr.Field != test.field {
+					t.Fatalf("error is %v, want a FieldError of %s", err, test.field)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("bound %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package dataset
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	// FormatGeoJSON is a FeatureCollection of points, see geojson.
+	FormatGeoJSON = "geojson"
+	// FormatXLSX is an Excel workbook, one sheet of it is read.
+	FormatXLSX = "xlsx"
+	// FormatZip is an archive of several dataset files, see Extract.
+	FormatZip = "zip"
+)
+
+// Record is a single row of a dataset file keyed by column name.
+type Record map[string]interface{}
+
+type Options struct {
+	// Aliases renames source columns to the json names of the models,
+	// e.g. "lat" -> "latitude".
+	Aliases map[string]string
+
+	// KeepLegacyId keeps the _id of MongoDB Extended JSON records as
+	// legacy_id instead of dropping it.
+	KeepLegacyId bool
+
+	// Mapping is the mapping profile chosen for the file, if any.
+	Mapping *Mapping
+
+	// Sheet is the sheet of a workbook to read, the first one if empty.
+	Sheet string
+}
+
+// Reader streams the records of a dataset file, so only the current record
+// is held in memory.
+type Reader interface {
+	// Read returns the next record, or io.EOF after the last one.
+	Read() (Record, error)
+	Close() error
+}
+
+// FormatFromFile picks the dataset format by file extension and falls back
+// to the Content-Type sent by the client. It returns "" if neither is known.
+// Gzip compressed files are named after their content, e.g. "cities.csv.gz".
+func FormatFromFile(fileName, contentType string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if ext == ".gz" {
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(fileName, filepath.Ext(fileName))))
+	}
+
+	switch ext {
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".csv":
+		return FormatCSV
+	case ".geojson":
+		return FormatGeoJSON
+	case ".xlsx":
+		return FormatXLSX
+	case ".zip":
+		return FormatZip
+	}
+
+	switch strings.TrimSpace(strings.Split(contentType, ";")[0]) {
+	case "application/json":
+		return FormatJSON
+	case "application/x-ndjson", "application/jsonl":
+		return FormatNDJSON
+	case "text/csv", "application/csv":
+		return FormatCSV
+	case "application/geo+json":
+		return FormatGeoJSON
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FormatXLSX
+	case "application/zip", "application/x-zip-compressed":
+		return FormatZip
+	}
+
+	return ""
+}
+
+// Open starts reading the file at path. Gzip compressed files are
+// decompressed on the fly.
+func Open(path, format string, opts Options) (Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{
+		file:         file,
+		aliases:      opts.Aliases,
+		keepLegacyId: opts.KeepLegacyId,
+		mapping:      opts.Mapping,
+	}
+
+	// A workbook is a zip, its sheets are read from the file directly.
+	if format == FormatXLSX {
+		r.next, r.sheet, err = xlsxRows(file, opts.Sheet)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		return r, nil
+	}
+
+	src, err := r.decompress()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSON, "":
+		r.next = jsonArray(src)
+	case FormatNDJSON:
+		r.next = ndjson(src)
+	case FormatCSV:
+		r.next, err = csvRows(src)
+	case FormatGeoJSON:
+		r.next = geojson(src)
+	default:
+		err = fmt.Errorf("unknown dataset format: %s", format)
+	}
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+type reader struct {
+	file         *os.File
+	gzip         *gzip.Reader
+	sheet        io.Closer
+	next         func() (Record, error)
+	aliases      map[string]string
+	keepLegacyId bool
+	mapping      *Mapping
+}
+
+// decompress returns the content of the file, unpacking it if it starts
+// with the gzip magic number.
+func (r *reader) decompress() (io.Reader, error) {
+	src := bufio.NewReader(r.file)
+
+	magic, _ := src.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return src, nil
+	}
+
+	var err error
+	r.gzip, err = gzip.NewReader(src)
+	return r.gzip, err
+}
+
+func (r *reader) Read() (Record, error) {
+	record, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+
+	record.extended(r.keepLegacyId)
+	if r.mapping != nil {
+		r.mapping.rename(record)
+	}
+	record.rename(r.aliases)
+	if r.mapping != nil {
+		r.mapping.apply(record)
+	}
+	return record, nil
+}
+
+func (r *reader) Close() error {
+	if r.gzip != nil {
+		r.gzip.Close()
+	}
+	if r.sheet != nil {
+		r.sheet.Close()
+	}
+	return r.file.Close()
+}
+
+func (r Record) rename(aliases map[string]string) {
+	for from, to := range aliases {
+		value, ok := r[from]
+		if !ok {
+			continue
+		}
+		if _, exists := r[to]; !exists {
+			r[to] = value
+		}
+		delete(r, from)
+	}
+}
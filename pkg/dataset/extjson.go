@@ -0,0 +1,96 @@
+package dataset
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// LegacyIdColumn holds the Mongo ObjectId of records read from an Extended
+// JSON export.
+const LegacyIdColumn = "legacy_id"
+
+// extendedKeys renames the bookkeeping fields of Mongo exports to our columns.
+var extendedKeys = map[string]string{
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+}
+
+// extended turns a record of a MongoDB Extended JSON export (relaxed or
+// canonical) into a plain one: {"$oid": ...}, {"$date": ...} and the
+// {"$numberInt": ...} family are unwrapped, createdAt and updatedAt become
+// created_at and updated_at, __v is dropped and _id is kept as legacy_id
+// if keepLegacyId is set.
+func (r Record) extended(keepLegacyId bool) {
+	for key, value := range r {
+		r[key] = unwrapExtended(value)
+	}
+
+	delete(r, "__v")
+
+	if id, ok := r["_id"]; ok {
+		delete(r, "_id")
+		if _, exists := r[LegacyIdColumn]; keepLegacyId && !exists {
+			r[LegacyIdColumn] = id
+		}
+	}
+
+	for from, to := range extendedKeys {
+		value, ok := r[from]
+		if !ok {
+			continue
+		}
+		if _, exists := r[to]; !exists {
+			r[to] = value
+		}
+		delete(r, from)
+	}
+}
+
+func unwrapExtended(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) != 1 {
+			for key, item := range v {
+				v[key] = unwrapExtended(item)
+			}
+			return v
+		}
+
+		for key, item := range v {
+			switch key {
+			case "$oid", "$symbol":
+				return cast.ToString(item)
+			case "$numberInt", "$numberLong", "$numberDouble", "$numberDecimal":
+				return json.Number(cast.ToString(item))
+			case "$date":
+				return extendedDate(unwrapExtended(item))
+			}
+			v[key] = unwrapExtended(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = unwrapExtended(item)
+		}
+		return v
+	}
+
+	return value
+}
+
+// extendedDate formats a $date value, which is an ISO-8601 string in relaxed
+// mode and milliseconds since the epoch in canonical mode, as RFC 3339 UTC.
+func extendedDate(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	ms, err := cast.ToInt64E(value)
+	if err != nil {
+		return value
+	}
+
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,263 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	// LatitudeField and LongitudeField hold the position of a GeoJSON
+	// feature in a record.
+	LatitudeField  = "latitude"
+	LongitudeField = "longitude"
+
+	// bufferSegments is how many sides the polygon of a buffer has.
+	bufferSegments = 32
+	// metersPerDegree is the length of a degree of latitude.
+	metersPerDegree = 111320
+)
+
+type geoFeature struct {
+	Type       string          `json:"type"`
+	Id         interface{}     `json:"id,omitempty"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties Record          `json:"properties"`
+}
+
+type geoGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []geoGeometry   `json:"geometries,omitempty"`
+}
+
+// geoShape is a geometry written by the Writer.
+type geoShape struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates,omitempty"`
+	Geometries  []geoShape  `json:"geometries,omitempty"`
+}
+
+// geojson decodes the features of a GeoJSON FeatureCollection one by one.
+// A feature becomes a record of its properties; the coordinates of its
+// point, or of the first point of a geometry collection, are stored in
+// latitude and longitude, and its id in guid if the properties have none.
+func geojson(r io.Reader) func() (Record, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	started := false
+	return func() (Record, error) {
+		if !started {
+			if err := geoFeatures(dec); err != nil {
+				return nil, err
+			}
+			started = true
+		}
+
+		// Members after the features are of no interest.
+		if !dec.More() {
+			return nil, io.EOF
+		}
+
+		var feature geoFeature
+		if err := dec.Decode(&feature); err != nil {
+			return nil, err
+		}
+		if feature.Type != "Feature" {
+			return nil, errors.New("geojson: features must be of type Feature")
+		}
+
+		record := feature.Properties
+		if record == nil {
+			record = Record{}
+		}
+		if _, ok := record["guid"]; !ok && feature.Id != nil {
+			record["guid"] = feature.Id
+		}
+
+		var geometry geoGeometry
+		if len(feature.Geometry) > 0 {
+			dec := json.NewDecoder(bytes.NewReader(feature.Geometry))
+			dec.UseNumber()
+			if err := dec.Decode(&geometry); err != nil {
+				return nil, err
+			}
+		}
+		if point, ok := geometry.point(); ok {
+			record[LongitudeField] = point[0]
+			record[LatitudeField] = point[1]
+		}
+
+		return record, nil
+	}
+}
+
+// geoFeatures moves dec into the features array of a FeatureCollection,
+// skipping the members before it.
+func geoFeatures(dec *json.Decoder) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return errors.New("geojson: file must contain a FeatureCollection")
+	}
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if token == "features" {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if delim, ok := token.(json.Delim); !ok || delim != '[' {
+				return errors.New("geojson: features must be an array")
+			}
+			return nil
+		}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+
+	return errors.New("geojson: file has no features")
+}
+
+// point returns the position of a Point, or of the first Point of a
+// GeometryCollection, as [longitude, latitude].
+func (g geoGeometry) point() ([]json.Number, bool) {
+	switch g.Type {
+	case "Point":
+		dec := json.NewDecoder(bytes.NewReader(g.Coordinates))
+		dec.UseNumber()
+
+		var position []json.Number
+		if err := dec.Decode(&position); err != nil || len(position) < 2 {
+			return nil, false
+		}
+		return position, true
+	case "GeometryCollection":
+		for _, geometry := range g.Geometries {
+			if position, ok := geometry.point(); ok {
+				return position, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// isFeatureCollection reports whether the JSON object text starts with is
+// a GeoJSON FeatureCollection. text may be cut off after its first members.
+func isFeatureCollection(text []byte) bool {
+	dec := json.NewDecoder(bytes.NewReader(text))
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return false
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		switch key {
+		case "features":
+			token, err := dec.Token()
+			return err == nil && token == json.Delim('[')
+		case "type":
+			value, err := dec.Token()
+			return err == nil && value == "FeatureCollection"
+		}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return false
+		}
+	}
+
+	return false
+}
+
+// feature encodes a record as a GeoJSON feature. The position is taken out
+// of the properties into a Point; with a buffer field holding a radius in
+// meters the geometry is a collection of the point and a polygon around it.
+func (w *Writer) feature(record Record) ([]byte, error) {
+	properties := make([]string, 0, len(w.fields))
+	for _, field := range w.fields {
+		if field != LatitudeField && field != LongitudeField {
+			properties = append(properties, field)
+		}
+	}
+
+	object, err := jsonObject(properties, record)
+	if err != nil {
+		return nil, err
+	}
+
+	feature := struct {
+		Type       string          `json:"type"`
+		Id         interface{}     `json:"id,omitempty"`
+		Geometry   interface{}     `json:"geometry"`
+		Properties json.RawMessage `json:"properties"`
+	}{
+		Type:       "Feature",
+		Id:         record["guid"],
+		Geometry:   w.geometry(record),
+		Properties: object,
+	}
+
+	return json.Marshal(feature)
+}
+
+func (w *Writer) geometry(record Record) interface{} {
+	lat, latErr := cast.ToFloat64E(record[LatitudeField])
+	lng, lngErr := cast.ToFloat64E(record[LongitudeField])
+	if record[LatitudeField] == nil || record[LongitudeField] == nil || latErr != nil || lngErr != nil {
+		return nil
+	}
+
+	point := geoShape{Type: "Point", Coordinates: []float64{lng, lat}}
+	if w.buffer == "" {
+		return point
+	}
+
+	radius, err := cast.ToFloat64E(record[w.buffer])
+	if err != nil || radius <= 0 {
+		return point
+	}
+
+	return geoShape{
+		Type: "GeometryCollection",
+		Geometries: []geoShape{
+			point,
+			{Type: "Polygon", Coordinates: [][][]float64{circle(lat, lng, radius)}},
+		},
+	}
+}
+
+// circle approximates the circle of radius meters around a position with a
+// ring of bufferSegments sides, counterclockwise as RFC 7946 wants it.
+func circle(lat, lng, radius float64) [][]float64 {
+	dLat := radius / metersPerDegree
+	dLng := radius / (metersPerDegree * math.Cos(lat*math.Pi/180))
+
+	ring := make([][]float64, 0, bufferSegments+1)
+	for i := 0; i < bufferSegments; i++ {
+		angle := 2 * math.Pi * float64(i) / bufferSegments
+		ring = append(ring, []float64{lng + dLng*math.Cos(angle), lat + dLat*math.Sin(angle)})
+	}
+
+	return append(ring, ring[0])
+}
@@ -0,0 +1,59 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// jsonArray decodes a top level JSON array element by element instead of
+// unmarshalling the whole file.
+func jsonArray(r io.Reader) func() (Record, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	started := false
+	return func() (Record, error) {
+		if !started {
+			token, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if delim, ok := token.(json.Delim); !ok || delim != '[' {
+				return nil, errors.New("json: file must contain an array of objects")
+			}
+			started = true
+		}
+
+		if !dec.More() {
+			// Consume the closing bracket, then every call ends with io.EOF.
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+
+		var record Record
+		if err := dec.Decode(&record); err != nil {
+			return nil, err
+		}
+
+		return record, nil
+	}
+}
+
+// ndjson decodes newline delimited JSON, one object per line.
+func ndjson(r io.Reader) func() (Record, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	return func() (Record, error) {
+		var record Record
+		if err := dec.Decode(&record); err != nil {
+			return nil, err
+		}
+
+		return record, nil
+	}
+}
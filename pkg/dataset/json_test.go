@@ -0,0 +1,155 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// writeJSONArray writes a JSON array of cities of at least size bytes and
+// returns its path and how many records it holds.
+func writeJSONArray(tb testing.TB, size int) (string, int) {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "cities.json")
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	w.WriteString("[")
+
+	written, count := 1, 0
+	for written < size {
+		if count > 0 {
+			w.WriteString(",\n")
+		}
+		n, _ := fmt.Fprintf(w, `{"guid":"00000000-0000-4000-8000-%012d","title":"City %d","city_code":"C%d","latitude":41.%06d,"longitude":69.%06d,"country_name":"Uzbekistan"}`,
+			count, count, count, count%1000000, count%1000000)
+		written += n + 2
+		count++
+	}
+
+	w.WriteString("]\n")
+	if err := w.Flush(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return path, count
+}
+
+// readAll reads every record of the JSON file at path. With every set it is
+// called after each record.
+func readAll(tb testing.TB, path string, every func(n int)) int {
+	tb.Helper()
+
+	reader, err := Open(path, FormatJSON, Options{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer reader.Close()
+
+	n := 0
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			return n
+		}
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		n++
+		if every != nil {
+			every(n)
+		}
+	}
+}
+
+func BenchmarkReadJSON(b *testing.B) {
+	path, count := writeJSONArray(b, 8<<20)
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(info.Size())
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if n := readAll(b, path, nil); n != count {
+			b.Fatalf("read %d records, want %d", n, count)
+		}
+	}
+}
+
+// TestReadJSONMemoryIsFlat reads a small and a sixteen times larger array:
+// the live heap while reading and the allocations per record must not grow
+// with the file.
+func TestReadJSONMemoryIsFlat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes and reads a 32 MB file")
+	}
+
+	type usage struct {
+		peak         uint64
+		allocsPerRow float64
+		records      int
+	}
+
+	measure := func(size int) usage {
+		path, count := writeJSONArray(t, size)
+
+		var stats runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		before := stats
+		var result usage
+
+		n := readAll(t, path, func(n int) {
+			if n%5000 != 0 {
+				return
+			}
+			runtime.GC()
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > before.HeapAlloc && stats.HeapAlloc-before.HeapAlloc > result.peak {
+				result.peak = stats.HeapAlloc - before.HeapAlloc
+			}
+		})
+		if n != count {
+			t.Fatalf("read %d records, want %d", n, count)
+		}
+
+		runtime.ReadMemStats(&stats)
+		result.records = n
+		// The forced collections allocate a little too, a per record
+		// comparison averages that out.
+		result.allocsPerRow = float64(stats.Mallocs-before.Mallocs) / float64(n)
+		return result
+	}
+
+	small := measure(2 << 20)
+	large := measure(32 << 20)
+	t.Logf("small: %d records, peak heap %d B, %.1f allocs/record", small.records, small.peak, small.allocsPerRow)
+	t.Logf("large: %d records, peak heap %d B, %.1f allocs/record", large.records, large.peak, large.allocsPerRow)
+
+	// The reader holds one record and its buffers, far less than a MB.
+	const maxLive = 1 << 20
+	if large.peak > maxLive {
+		t.Errorf("live heap grew by %d B reading a 32 MB file, want under %d B", large.peak, maxLive)
+	}
+	if large.peak > 2*small.peak+64<<10 {
+		t.Errorf("live heap grew with the file: %d B for 2 MB, %d B for 32 MB", small.peak, large.peak)
+	}
+
+	if large.allocsPerRow > small.allocsPerRow*1.1 {
+		t.Errorf("allocations per record grew with the file: %.1f for 2 MB, %.1f for 32 MB", small.allocsPerRow, large.allocsPerRow)
+	}
+}
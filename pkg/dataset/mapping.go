@@ -0,0 +1,137 @@
+package dataset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Transforms of a Mapping.
+const (
+	TransformTrim   = "trim"
+	TransformUpper  = "upper"
+	TransformLower  = "lower"
+	TransformNumber = "number"
+)
+
+// Mapping reshapes the records of a source file whose fields are named or
+// formatted differently from the models. Rename runs before the column
+// aliases, the other steps after them, in the order of the fields here, so
+// they refer to the json names of the models.
+type Mapping struct {
+	// Rename maps source columns to field names, e.g. "address" -> "adress".
+	Rename map[string]string
+	// Drop removes fields the model should not see.
+	Drop []string
+	// Transforms lists the transforms applied to each field, in order.
+	Transforms map[string][]string
+	// Defaults fill fields that are missing or empty.
+	Defaults map[string]interface{}
+}
+
+// Check reports the first unknown transform of m.
+func (m *Mapping) Check() error {
+	for field, transforms := range m.Transforms {
+		for _, name := range transforms {
+			switch name {
+			case TransformTrim, TransformUpper, TransformLower, TransformNumber:
+			default:
+				return fmt.Errorf("%s: unknown transform %q", field, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rename applies Rename to the record. CSV headers are lower-cased, so a
+// source column is also looked up in lower case.
+func (m *Mapping) rename(r Record) {
+	for from, to := range m.Rename {
+		value, ok := r[from]
+		if !ok {
+			from = strings.ToLower(from)
+			value, ok = r[from]
+		}
+		if !ok || from == to {
+			continue
+		}
+		if _, exists := r[to]; !exists {
+			r[to] = value
+		}
+		delete(r, from)
+	}
+}
+
+// apply runs every step of m but Rename on the record.
+func (m *Mapping) apply(r Record) {
+	for _, field := range m.Drop {
+		delete(r, field)
+	}
+
+	for field, transforms := range m.Transforms {
+		value, ok := r[field]
+		if !ok {
+			continue
+		}
+		for _, name := range transforms {
+			value = transform(name, value)
+		}
+		r[field] = value
+	}
+
+	for field, value := range m.Defaults {
+		if current, ok := r[field]; !ok || current == nil || current == "" {
+			r[field] = value
+		}
+	}
+}
+
+// transform applies one transform to a value. Only strings are changed; a
+// string that is not a number is left as it is, so binding reports it as a
+// bad value of the row.
+func transform(name string, value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	switch name {
+	case TransformTrim:
+		return strings.TrimSpace(s)
+	case TransformUpper:
+		return strings.ToUpper(s)
+	case TransformLower:
+		return strings.ToLower(s)
+	case TransformNumber:
+		return parseNumber(s)
+	}
+
+	return value
+}
+
+// parseNumber reads a number whose decimal separator is a point or a comma
+// and whose thousands are grouped by spaces or underscores, e.g. "1 234,5".
+// A comma is always the decimal separator, so "1,234" is 1.234; a number
+// with both a point and a comma, or with several commas, is ambiguous and
+// left as it is, as is anything else that is not a number.
+func parseNumber(s string) interface{} {
+	n := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '_':
+			return -1
+		}
+		return r
+	}, s)
+	if n == "" || strings.Count(n, ",") > 1 || strings.Contains(n, ",") && strings.Contains(n, ".") {
+		return s
+	}
+	n = strings.Replace(n, ",", ".", 1)
+
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return s
+	}
+
+	return f
+}
@@ -0,0 +1,30 @@
+package dataset
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"42", 42.0},
+		{"-0.5", -0.5},
+		{"1 234,5", 1234.5},
+		{"1 234 567", 1234567.0},
+		{"1_000", 1000.0},
+		{"41,2995", 41.2995},
+		{"1,234", 1.234},
+		// Ambiguous: the comma cannot be a decimal separator here.
+		{"1,234,567", "1,234,567"},
+		{"1,234.5", "1,234.5"},
+		{"1.234,5", "1.234,5"},
+		{"", ""},
+		{"abc", "abc"},
+	}
+
+	for _, test := range tests {
+		if got := parseNumber(test.in); got != test.want {
+			t.Errorf("parseNumber(%q) = %#v, want %#v", test.in, got, test.want)
+		}
+	}
+}
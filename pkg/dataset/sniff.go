@@ -0,0 +1,176 @@
+package dataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// SniffLen is how many leading bytes Sniff needs.
+const SniffLen = 4096
+
+// ErrUnknownFormat means a file is neither JSON, NDJSON, GeoJSON, CSV, a
+// workbook nor a zip.
+var ErrUnknownFormat = errors.New("content is not JSON, NDJSON, GeoJSON, CSV, XLSX or a zip archive")
+
+// Sniff tells the format of a dataset from its first bytes, looking inside
+// gzip. A zip that starts with the parts of a workbook is XLSX, a top level
+// array is JSON, a FeatureCollection GeoJSON, any other
+// top level object NDJSON (the NDJSON reader also takes a single object
+// spread over several lines) and any other text CSV. It returns "" for
+// binary content.
+func Sniff(head []byte) string {
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06")) {
+		if isWorkbook(head) {
+			return FormatXLSX
+		}
+		return FormatZip
+	}
+
+	if bytes.HasPrefix(head, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(head))
+		if err != nil {
+			return ""
+		}
+
+		// head cuts the stream short, what was inflated up to there is enough.
+		inflated, _ := io.ReadAll(io.LimitReader(gz, SniffLen))
+		if bytes.HasPrefix(inflated, []byte{0x1f, 0x8b}) {
+			return ""
+		}
+		if format := Sniff(inflated); format != FormatZip && format != FormatXLSX {
+			return format
+		}
+		return ""
+	}
+
+	text := bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	text = bytes.TrimLeft(text, " \t\r\n")
+	if len(text) == 0 || !isText(text) {
+		return ""
+	}
+
+	switch text[0] {
+	case '[':
+		return FormatJSON
+	case '{':
+		if isFeatureCollection(text) {
+			return FormatGeoJSON
+		}
+		return FormatNDJSON
+	}
+
+	return FormatCSV
+}
+
+// Head reads the first SniffLen bytes of r, or all of it if it is shorter.
+func Head(r io.Reader) ([]byte, error) {
+	head := make([]byte, SniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	return head[:n], nil
+}
+
+// HeadFile reads the first SniffLen bytes of the file at path.
+func HeadFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Head(file)
+}
+
+// SniffFile runs Sniff on the beginning of the file at path.
+func SniffFile(path string) (string, error) {
+	head, err := HeadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return Sniff(head), nil
+}
+
+// Detect picks the format of a file from its content and checks it against
+// the extension of fileName; the Content-Type sent by the client is not
+// trusted. JSON and NDJSON go by content, as the extension is often wrong
+// between the two. With a nil head only the name is checked.
+func Detect(fileName string, head []byte) (string, error) {
+	named := FormatFromFile(fileName, "")
+	if head == nil {
+		if named == "" {
+			return "", ErrUnknownFormat
+		}
+		return named, nil
+	}
+
+	return confirm(named, Sniff(head))
+}
+
+// confirm checks the format a file claims against the sniffed one and
+// returns the format to read it with.
+func confirm(claimed, sniffed string) (string, error) {
+	switch {
+	case sniffed == "":
+		return "", ErrUnknownFormat
+	case claimed == FormatGeoJSON && isJSON(sniffed):
+		// A feature collection is only told by its "type", which a large
+		// crs, bbox or properties can push past head.
+		return FormatGeoJSON, nil
+	case claimed == "", claimed == sniffed, isJSON(claimed) && isJSON(sniffed):
+		return sniffed, nil
+	case claimed == FormatXLSX && sniffed == FormatZip:
+		// The first part of a workbook is not always one Sniff knows.
+		return FormatXLSX, nil
+	}
+
+	return "", fmt.Errorf("file is declared %s but contains %s", claimed, sniffed)
+}
+
+// isWorkbook reports whether the first entry of the zip that head starts
+// with is a part of an Office Open XML workbook.
+func isWorkbook(head []byte) bool {
+	if len(head) < 30 {
+		return false
+	}
+
+	size := int(binary.LittleEndian.Uint16(head[26:28]))
+	if len(head) < 30+size {
+		return false
+	}
+
+	name := string(head[30 : 30+size])
+	return name == "[Content_Types].xml" || strings.HasPrefix(name, "xl/") ||
+		strings.HasPrefix(name, "_rels/") || strings.HasPrefix(name, "docProps/")
+}
+
+func isJSON(format string) bool {
+	return format == FormatJSON || format == FormatNDJSON || format == FormatGeoJSON
+}
+
+// isText reports whether b is UTF-8 without control characters other than
+// tabs and line breaks. A rune cut off at the end of b is allowed.
+func isText(b []byte) bool {
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size == 1 {
+			return len(b) < utf8.UTFMax && !utf8.FullRune(b)
+		}
+		if r < 0x20 && r != '\t' && r != '\r' && r != '\n' {
+			return false
+		}
+		b = b[size:]
+	}
+
+	return true
+}
@@ -0,0 +1,50 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	// The type of this collection is past what Sniff looks at.
+	bigCollection := `{"crs":{"properties":{"name":"` + strings.Repeat("x", SniffLen) + `"}},"type":"FeatureCollection","features":[]}`
+
+	tests := []struct {
+		name     string
+		fileName string
+		head     string
+		want     string
+	}{
+		{"array named .ndjson", "cities.ndjson", `[{"guid":"1"}]`, FormatJSON},
+		{"lines named .json", "cities.json", "{\"guid\":\"1\"}\n{\"guid\":\"2\"}\n", FormatNDJSON},
+		{"collection named .json", "cities.json", `{"type":"FeatureCollection","features":[]}`, FormatGeoJSON},
+		{"large collection named .geojson", "cities.geojson", bigCollection, FormatGeoJSON},
+		{"collection without an extension", "cities", `{"type":"FeatureCollection","features":[]}`, FormatGeoJSON},
+		{"CSV", "cities.csv", "guid,title\n1,Tashkent\n", FormatCSV},
+	}
+
+	for _, test := range tests {
+		head := []byte(test.head)
+		if len(head) > SniffLen {
+			head = head[:SniffLen]
+		}
+
+		got, err := Detect(test.fileName, head)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: format is %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDetectMismatch(t *testing.T) {
+	if _, err := Detect("cities.csv", []byte(`[{"guid":"1"}]`)); err == nil {
+		t.Error("JSON named .csv did not fail")
+	}
+	if _, err := Detect("cities.json", []byte("\x00\x01\x02")); err != ErrUnknownFormat {
+		t.Errorf("binary content: error is %v, want ErrUnknownFormat", err)
+	}
+}
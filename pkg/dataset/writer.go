@@ -0,0 +1,192 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cast"
+)
+
+// Writer writes records in one of the dataset formats, so that Open reads
+// them back. Fields fixes the keys written and their order, which is also
+// the CSV header.
+type Writer struct {
+	format string
+	fields []string
+	buffer string
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	book   *Workbook
+	count  int
+}
+
+type WriterOptions struct {
+	// Buffer is the field holding the radius in meters of the polygon
+	// GeoJSON features get around their point, if any.
+	Buffer string
+
+	// Sheet names the sheet of an XLSX file.
+	Sheet string
+}
+
+func NewWriter(w io.Writer, format string, fields []string, opts WriterOptions) (*Writer, error) {
+	writer := &Writer{
+		format: format,
+		fields: fields,
+		buffer: opts.Buffer,
+		buf:    bufio.NewWriter(w),
+	}
+
+	switch format {
+	case FormatJSON:
+		writer.buf.WriteString("[")
+	case FormatGeoJSON:
+		if !hasField(fields, LatitudeField) || !hasField(fields, LongitudeField) {
+			return nil, errors.New("geojson: records have no latitude and longitude")
+		}
+		writer.buf.WriteString(`{"type":"FeatureCollection","features":[`)
+	case FormatNDJSON:
+	case FormatXLSX:
+		writer.book = NewWorkbook(writer.buf)
+		if err := writer.book.AddSheet(opts.Sheet, fields); err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		writer.csv = csv.NewWriter(writer.buf)
+		if err := writer.csv.Write(fields); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown dataset format: %s", format)
+	}
+
+	return writer, nil
+}
+
+// Write adds a record. Keys missing from the record are written as null, or
+// as an empty CSV cell.
+func (w *Writer) Write(record Record) error {
+	defer func() { w.count++ }()
+
+	if w.book != nil {
+		return w.book.Write(record)
+	}
+
+	if w.csv != nil {
+		row := make([]string, len(w.fields))
+		for i, field := range w.fields {
+			if value := record[field]; value != nil {
+				row[i] = cast.ToString(value)
+			}
+		}
+		return w.csv.Write(row)
+	}
+
+	var (
+		object []byte
+		err    error
+	)
+	if w.format == FormatGeoJSON {
+		object, err = w.feature(record)
+	} else {
+		object, err = jsonObject(w.fields, record)
+	}
+	if err != nil {
+		return err
+	}
+
+	if w.format == FormatJSON || w.format == FormatGeoJSON {
+		if w.count > 0 {
+			w.buf.WriteString(",")
+		}
+		w.buf.WriteString("\n  ")
+	}
+	w.buf.Write(object)
+	if w.format == FormatNDJSON {
+		w.buf.WriteString("\n")
+	}
+
+	return nil
+}
+
+// jsonObject encodes the record as a JSON object with the keys in the order
+// of fields.
+func jsonObject(fields []string, record Record) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		key, err := json.Marshal(field)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(record[field])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+
+	return b.Bytes(), nil
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if w.book != nil {
+		if err := w.book.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return w.buf.Flush()
+}
+
+// Close ends the file and flushes it. It does not close the underlying
+// writer.
+func (w *Writer) Close() error {
+	if w.format == FormatJSON || w.format == FormatGeoJSON {
+		if w.count > 0 {
+			w.buf.WriteString("\n")
+		}
+		w.buf.WriteString("]")
+		if w.format == FormatGeoJSON {
+			w.buf.WriteString("}")
+		}
+		w.buf.WriteString("\n")
+	}
+	if w.book != nil {
+		if err := w.book.Close(); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func hasField(fields []string, name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,658 @@
+package dataset
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	xlsxMainNS     = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelationNS = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// timestampLayout is how timestamps are read from and written to date
+// cells, the way PostgreSQL prints a timestamp in JSON.
+const timestampLayout = "2006-01-02T15:04:05.999999"
+
+type xlsxWorkbook struct {
+	Properties struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		Id   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelations struct {
+	Relations []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		Id   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtId int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxRow struct {
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref    string    `xml:"r,attr"`
+	Type   string    `xml:"t,attr"`
+	Style  int       `xml:"s,attr"`
+	Value  *string   `xml:"v"`
+	Inline *xlsxText `xml:"is"`
+}
+
+// xlsxBook is the part of a workbook needed to read its sheets.
+type xlsxBook struct {
+	entries  map[string]*zip.File
+	sheets   []string
+	targets  map[string]string
+	strings  []string
+	dates    map[int]bool
+	date1904 bool
+}
+
+// Sheets lists the sheet names of the workbook at path in their order.
+func Sheets(path string) ([]string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	book, err := openBook(&archive.Reader, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return book.sheets, nil
+}
+
+// openBook reads the workbook part of an xlsx archive, with the shared
+// strings and styles if cells are going to be read.
+func openBook(archive *zip.Reader, cells bool) (*xlsxBook, error) {
+	book := &xlsxBook{
+		entries: make(map[string]*zip.File, len(archive.File)),
+		targets: make(map[string]string),
+		dates:   make(map[int]bool),
+	}
+	for _, entry := range archive.File {
+		book.entries[strings.TrimPrefix(entry.Name, "/")] = entry
+	}
+
+	var workbook xlsxWorkbook
+	if err := book.decode("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var relations xlsxRelations
+	if err := book.decode("xl/_rels/workbook.xml.rels", &relations); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(relations.Relations))
+	for _, relation := range relations.Relations {
+		target := relation.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		ids[relation.Id] = target
+	}
+	for _, sheet := range workbook.Sheets {
+		book.sheets = append(book.sheets, sheet.Name)
+		book.targets[sheet.Name] = ids[sheet.Id]
+	}
+	book.date1904 = workbook.Properties.Date1904 == "1" || workbook.Properties.Date1904 == "true"
+
+	if !cells {
+		return book, nil
+	}
+
+	if _, ok := book.entries["xl/sharedStrings.xml"]; ok {
+		var shared struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := book.decode("xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+		book.strings = make([]string, len(shared.Items))
+		for i, item := range shared.Items {
+			book.strings[i] = item.String()
+		}
+	}
+
+	if _, ok := book.entries["xl/styles.xml"]; ok {
+		var styles xlsxStyles
+		if err := book.decode("xl/styles.xml", &styles); err != nil {
+			return nil, err
+		}
+		custom := make(map[int]string, len(styles.NumFmts))
+		for _, format := range styles.NumFmts {
+			custom[format.Id] = format.Code
+		}
+		for i, xf := range styles.CellXfs {
+			book.dates[i] = isDateFormat(xf.NumFmtId, custom[xf.NumFmtId])
+		}
+	}
+
+	return book, nil
+}
+
+func (b *xlsxBook) decode(name string, v interface{}) error {
+	entry, ok := b.entries[name]
+	if !ok {
+		return fmt.Errorf("xlsx: %s is missing", name)
+	}
+
+	src, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := xml.NewDecoder(src).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: %s: %w", name, err)
+	}
+	return nil
+}
+
+// xlsxRows reads the sheet of the workbook in file, the first one if sheet
+// is empty. The first row that is not empty is the header, which is
+// trimmed and lower-cased like a CSV header. Numbers become json.Number,
+// cells formatted as dates timestamps and empty cells are left out.
+func xlsxRows(file *os.File, sheet string) (func() (Record, error), io.Closer, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	book, err := openBook(archive, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sheet == "" {
+		if len(book.sheets) == 0 {
+			return nil, nil, errors.New("xlsx: workbook has no sheets")
+		}
+		sheet = book.sheets[0]
+	}
+	target, ok := book.targets[sheet]
+	if !ok {
+		return nil, nil, fmt.Errorf("xlsx: workbook has no sheet %q", sheet)
+	}
+	entry, ok := book.entries[target]
+	if !ok {
+		return nil, nil, fmt.Errorf("xlsx: %s of sheet %q is missing", target, sheet)
+	}
+
+	src, err := entry.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	dec := xml.NewDecoder(src)
+
+	var header []string
+	next := func() (Record, error) {
+		for {
+			row, err := nextRow(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			record := make(Record, len(row.Cells))
+			for i, cell := range row.Cells {
+				column := i
+				if cell.Ref != "" {
+					if column, err = cellColumn(cell.Ref); err != nil {
+						return nil, err
+					}
+				}
+
+				value, err := book.value(cell)
+				if err != nil {
+					return nil, fmt.Errorf("xlsx: %s: %w", cell.Ref, err)
+				}
+				if value == nil {
+					continue
+				}
+
+				if header == nil {
+					record[strconv.Itoa(column)] = value
+				} else if column < len(header) && header[column] != "" {
+					record[header[column]] = value
+				}
+			}
+			if len(record) == 0 {
+				continue
+			}
+
+			if header == nil {
+				header = xlsxHeader(record)
+				continue
+			}
+			return record, nil
+		}
+	}
+
+	return next, src, nil
+}
+
+// nextRow decodes the next row element of a sheet.
+func nextRow(dec *xml.Decoder) (*xlsxRow, error) {
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+		return &row, nil
+	}
+}
+
+// xlsxHeader turns the first row, keyed by column index, into the names of
+// the columns.
+func xlsxHeader(row Record) []string {
+	var header []string
+	for key, value := range row {
+		column, _ := strconv.Atoi(key)
+		for len(header) <= column {
+			header = append(header, "")
+		}
+		header[column] = strings.ToLower(strings.TrimSpace(fmt.Sprint(value)))
+	}
+
+	return header
+}
+
+// value converts a cell to a record value, nil for an empty cell.
+func (b *xlsxBook) value(cell xlsxCell) (interface{}, error) {
+	switch cell.Type {
+	case "inlineStr":
+		if cell.Inline == nil {
+			return nil, nil
+		}
+		return cell.Inline.String(), nil
+	}
+
+	if cell.Value == nil {
+		return nil, nil
+	}
+	v := *cell.Value
+
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i >= len(b.strings) {
+			return nil, fmt.Errorf("unknown shared string %s", v)
+		}
+		return b.strings[i], nil
+	case "str", "e":
+		return v, nil
+	case "b":
+		return v == "1", nil
+	case "d":
+		return v, nil
+	}
+
+	if v == "" {
+		return nil, nil
+	}
+	if b.dates[cell.Style] {
+		serial, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		return serialTime(serial, b.date1904).Format(timestampLayout), nil
+	}
+	return json.Number(v), nil
+}
+
+// cellColumn returns the zero based column of a cell reference like "AB12".
+func cellColumn(ref string) (int, error) {
+	column := 0
+	for i, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			column = column*26 + int(r-'A') + 1
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		return column - 1, nil
+	}
+
+	return 0, fmt.Errorf("xlsx: bad cell reference %q", ref)
+}
+
+// columnName is the letters of the zero based column, "A" for 0.
+func columnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+
+	return name
+}
+
+// isDateFormat reports whether a number format shows a date or a time:
+// one of the built-in date formats or a code with date or time parts once
+// quoted text, colours and escaped characters are taken out.
+func isDateFormat(id int, code string) bool {
+	switch {
+	case id >= 14 && id <= 22, id >= 27 && id <= 36, id >= 45 && id <= 47, id >= 50 && id <= 58:
+		return true
+	case code == "":
+		return false
+	}
+
+	var (
+		b      strings.Builder
+		quoted bool
+		square bool
+		escape bool
+	)
+	for _, r := range code {
+		switch {
+		case escape:
+			escape = false
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			square = true
+		case r == ']':
+			square = false
+		case square:
+		case r == '\\' || r == '_' || r == '*':
+			escape = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return strings.ContainsAny(strings.ToLower(b.String()), "ymdhs")
+}
+
+// serialBase is day zero of the 1900 date system. It is the last day of
+// 1899 rather than the first of 1900 because Excel counts 29 February 1900.
+var (
+	serialBase     = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	serialBase1904 = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// serialTime converts a date serial number to a time, to the microsecond.
+func serialTime(serial float64, date1904 bool) time.Time {
+	base := serialBase
+	if date1904 {
+		base = serialBase1904
+	}
+
+	days := math.Floor(serial)
+	micros := math.Round((serial - days) * 86400e6)
+	return base.AddDate(0, 0, int(days)).Add(time.Duration(micros) * time.Microsecond)
+}
+
+// timeSerial is the date serial number of t in the 1900 date system.
+func timeSerial(t time.Time) float64 {
+	seconds := t.Unix() - serialBase.Unix()
+	return float64(seconds)/86400 + float64(t.Nanosecond())/86400e9
+}
+
+// Workbook writes an xlsx file sheet by sheet, streaming the rows to the
+// underlying writer. Strings are stored inline, so nothing has to be held
+// back for a shared string table.
+type Workbook struct {
+	zip    *zip.Writer
+	sheet  io.Writer
+	sheets []string
+	fields []string
+	row    int
+}
+
+// Cell styles of styles.xml.
+const (
+	xlsxStyleDate   = 1
+	xlsxStyleHeader = 2
+)
+
+func NewWorkbook(w io.Writer) *Workbook {
+	return &Workbook{zip: zip.NewWriter(w)}
+}
+
+// AddSheet ends the current sheet and starts the next one with a header
+// row of fields. Sheet names are cut to the 31 characters Excel allows.
+func (b *Workbook) AddSheet(name string, fields []string) error {
+	if err := b.endSheet(); err != nil {
+		return err
+	}
+
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	if strings.ContainsAny(name, `[]:*?/\`) || name == "" {
+		return fmt.Errorf("xlsx: bad sheet name %q", name)
+	}
+	for _, sheet := range b.sheets {
+		if strings.EqualFold(sheet, name) {
+			return fmt.Errorf("xlsx: duplicate sheet name %q", name)
+		}
+	}
+
+	sheet, err := b.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(b.sheets)+1))
+	if err != nil {
+		return err
+	}
+	b.sheet = sheet
+	b.sheets = append(b.sheets, name)
+	b.fields = fields
+	b.row = 0
+
+	_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="`+xlsxMainNS+`">`+
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`+
+		`<sheetData>`)
+	if err != nil {
+		return err
+	}
+
+	header := make(Record, len(fields))
+	for _, field := range fields {
+		header[field] = field
+	}
+	return b.writeRow(header, xlsxStyleHeader)
+}
+
+// Write adds a row to the current sheet. Numbers and booleans get typed
+// cells, timestamps date cells and other values string cells.
+func (b *Workbook) Write(record Record) error {
+	if b.sheet == nil {
+		return errors.New("xlsx: no sheet to write to")
+	}
+
+	return b.writeRow(record, 0)
+}
+
+func (b *Workbook) writeRow(record Record, style int) error {
+	b.row++
+
+	var row strings.Builder
+	fmt.Fprintf(&row, `<row r="%d">`, b.row)
+	for i, field := range b.fields {
+		value := record[field]
+		if value == nil {
+			continue
+		}
+
+		ref := columnName(i) + strconv.Itoa(b.row)
+		switch v := value.(type) {
+		case json.Number:
+			fmt.Fprintf(&row, `<c r="%s"><v>%s</v></c>`, ref, v)
+		case float64, float32, int, int64, int32:
+			fmt.Fprintf(&row, `<c r="%s"><v>%v</v></c>`, ref, v)
+		case bool:
+			bit := 0
+			if v {
+				bit = 1
+			}
+			fmt.Fprintf(&row, `<c r="%s" t="b"><v>%d</v></c>`, ref, bit)
+		default:
+			text := fmt.Sprint(v)
+			if t, err := time.Parse(timestampLayout, text); err == nil && style == 0 {
+				fmt.Fprintf(&row, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDate, strconv.FormatFloat(timeSerial(t), 'f', -1, 64))
+				continue
+			}
+
+			if style != 0 {
+				fmt.Fprintf(&row, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+			} else {
+				fmt.Fprintf(&row, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			}
+			xml.EscapeText(&row, []byte(text))
+			row.WriteString(`</t></is></c>`)
+		}
+	}
+	row.WriteString(`</row>`)
+
+	_, err := io.WriteString(b.sheet, row.String())
+	return err
+}
+
+// Flush writes the rows so far to the underlying writer.
+func (b *Workbook) Flush() error {
+	return b.zip.Flush()
+}
+
+func (b *Workbook) endSheet() error {
+	if b.sheet == nil {
+		return nil
+	}
+
+	_, err := io.WriteString(b.sheet, `</sheetData></worksheet>`)
+	b.sheet = nil
+	return err
+}
+
+// Close ends the last sheet and writes the parts that list the sheets. It
+// does not close the underlying writer.
+func (b *Workbook) Close() error {
+	if len(b.sheets) == 0 {
+		return errors.New("xlsx: workbook has no sheets")
+	}
+	if err := b.endSheet(); err != nil {
+		return err
+	}
+
+	var (
+		types     strings.Builder
+		sheets    strings.Builder
+		relations strings.Builder
+	)
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	relations.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, name := range b.sheets {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&relations, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+
+		fmt.Fprintf(&sheets, `<sheet name="`)
+		xml.EscapeText(&sheets, []byte(name))
+		fmt.Fprintf(&sheets, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	types.WriteString(`</Types>`)
+	fmt.Fprintf(&relations, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(b.sheets)+1)
+	relations.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="` + xlsxMainNS + `" xmlns:r="` + xlsxRelationNS + `"><sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", relations.String()},
+		{"xl/styles.xml", xlsxStylesPart},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"[Content_Types].xml", types.String()},
+	}
+	for _, part := range parts {
+		w, err := b.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	return b.zip.Close()
+}
+
+// xlsxStylesPart has the plain style, the date style and the bold header.
+const xlsxStylesPart = xml.Header + `<styleSheet xmlns="` + xlsxMainNS + `">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,249 @@
+package dataset
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeXLSX writes a workbook made of the given parts, keyed by their name
+// in the archive, and returns its path.
+func writeXLSX(tb testing.TB, parts map[string]string) string {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "book.xlsx")
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	w := zip.NewWriter(file)
+	for name, content := range parts {
+		part, err := w.Create(name)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := io.WriteString(part, content); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return path
+}
+
+// readXLSX reads every record of the sheet of the workbook at path.
+func readXLSX(tb testing.TB, path, sheet string) []Record {
+	tb.Helper()
+
+	reader, err := Open(path, FormatXLSX, Options{Sheet: sheet})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer reader.Close()
+
+	var records []Record
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			tb.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+// xlsxParts are the parts of a workbook with the sheets "notes" and
+// "cities", the second of which has shared and inline strings, a skipped
+// column and a date. workbookPr is put into workbook.xml as is.
+func xlsxParts(workbookPr string) map[string]string {
+	return map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="` + xlsxMainNS + `" xmlns:r="` + xlsxRelationNS + `">` + workbookPr + `
+	<sheets>
+		<sheet name="notes" sheetId="1" r:id="rId1"/>
+		<sheet name="cities" sheetId="2" r:id="rId2"/>
+	</sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+	<Relationship Id="rId1" Target="worksheets/sheet1.xml"/>
+	<Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="` + xlsxMainNS + `">
+	<si><t>Title</t></si>
+	<si><r><t>Tash</t></r><r><t>kent</t></r></si>
+	<si><t>note</t></si>
+</sst>`,
+		"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<styleSheet xmlns="` + xlsxMainNS + `">
+	<numFmts><numFmt numFmtId="164" formatCode="[$-409]dd/mm/yyyy\ hh:mm"/></numFmts>
+	<cellXfs>
+		<xf numFmtId="0"/>
+		<xf numFmtId="14"/>
+		<xf numFmtId="164"/>
+		<xf numFmtId="4"/>
+	</cellXfs>
+</styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="` + xlsxMainNS + `"><sheetData>
+	<row r="1"><c r="A1" t="s"><v>2</v></c></row>
+	<row r="2"><c r="A2" t="inlineStr"><is><t>not a city</t></is></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="` + xlsxMainNS + `"><sheetData>
+	<row r="1"></row>
+	<row r="2">
+		<c r="A2" t="s"><v>0</v></c>
+		<c r="C2" t="inlineStr"><is><t> Founded </t></is></c>
+		<c r="D2" t="inlineStr"><is><t>Population</t></is></c>
+		<c r="E2" t="inlineStr"><is><t>Capital</t></is></c>
+		<c r="F2" t="inlineStr"><is><t>Updated</t></is></c>
+	</row>
+	<row r="3">
+		<c r="A3" t="s"><v>1</v></c>
+		<c r="C3" s="1"><v>45000</v></c>
+		<c r="D3" s="3"><v>2956384</v></c>
+		<c r="E3" t="b"><v>1</v></c>
+		<c r="F3" s="2"><v>45000.5</v></c>
+	</row>
+	<row r="4">
+		<c r="A4" t="inlineStr"><is><t>Samarkand &amp; Bukhara</t></is></c>
+		<c r="B4" t="inlineStr"><is><t>under no header</t></is></c>
+		<c r="E4" t="b"><v>0</v></c>
+	</row>
+</sheetData></worksheet>`,
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	tests := []struct {
+		name       string
+		workbookPr string
+		founded    string
+		updated    string
+	}{
+		{"1900 date system", ``, "2023-03-15T00:00:00", "2023-03-15T12:00:00"},
+		{"1904 date system", `<workbookPr date1904="1"/>`, "2027-03-16T00:00:00", "2027-03-16T12:00:00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeXLSX(t, xlsxParts(test.workbookPr))
+
+			got := readXLSX(t, path, "cities")
+			want := []Record{
+				{
+					"title":      "Tashkent",
+					"founded":    test.founded,
+					"population": json.Number("2956384"),
+					"capital":    true,
+					"updated":    test.updated,
+				},
+				{
+					"title":   "Samarkand & Bukhara",
+					"capital": false,
+				},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("records are\n%#v\nwant\n%#v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadXLSXSheets(t *testing.T) {
+	path := writeXLSX(t, xlsxParts(""))
+
+	sheets, err := Sheets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"notes", "cities"}; !reflect.DeepEqual(sheets, want) {
+		t.Errorf("sheets are %q, want %q", sheets, want)
+	}
+
+	// The first sheet is read if none is named.
+	got := readXLSX(t, path, "")
+	if want := []Record{{"note": "not a city"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first sheet is %#v, want %#v", got, want)
+	}
+
+	if _, err := Open(path, FormatXLSX, Options{Sheet: "airports"}); err == nil {
+		t.Error("opening a missing sheet did not fail")
+	}
+}
+
+func TestWorkbookRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := NewWorkbook(file)
+	sheets := []struct {
+		name    string
+		fields  []string
+		records []Record
+	}{
+		{
+			name:   "country",
+			fields: []string{"guid", "title", "code"},
+			records: []Record{
+				{"guid": "c1", "title": "Uzbekistan", "code": "UZ"},
+				{"guid": "c2", "title": `<Côte d'Ivoire> & "CI"`, "code": "CI"},
+			},
+		},
+		{
+			name:   "city",
+			fields: []string{"guid", "title", "latitude", "offset", "capital", "created_at"},
+			records: []Record{
+				{"guid": "t1", "title": " Tashkent ", "latitude": json.Number("41.2995"), "offset": 5, "capital": true, "created_at": "2023-03-15T12:30:45.5"},
+				// A missing value is a skipped cell, the columns after it
+				// keep their place.
+				{"guid": "t2", "latitude": 39.6542, "capital": false},
+			},
+		},
+	}
+
+	for _, sheet := range sheets {
+		if err := book.AddSheet(sheet.name, sheet.fields); err != nil {
+			t.Fatal(err)
+		}
+		for _, record := range sheet.records {
+			if err := book.Write(record); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := book.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]Record{
+		"country": sheets[0].records,
+		"city": {
+			{"guid": "t1", "title": " Tashkent ", "latitude": json.Number("41.2995"), "offset": json.Number("5"), "capital": true, "created_at": "2023-03-15T12:30:45.5"},
+			{"guid": "t2", "latitude": json.Number("39.6542"), "capital": false},
+		},
+	}
+	for name, records := range want {
+		if got := readXLSX(t, path, name); !reflect.DeepEqual(got, records) {
+			t.Errorf("sheet %s is\n%#v\nwant\n%#v", name, got, records)
+		}
+	}
+}
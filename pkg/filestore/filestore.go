@@ -0,0 +1,168 @@
+package filestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"ret/pkg/blob"
+	"sync"
+)
+
+// Store keeps uploaded files in a blob store, content-addressed by their
+// SHA-256, so the same bytes are stored once no matter what the client
+// called them. dir is local scratch space for files on their way in or
+// out, and for chunked uploads.
+type Store struct {
+	dir   string
+	blobs blob.Store
+
+	mu sync.Mutex
+	// busy holds the parts a chunk is being written to.
+	busy map[string]bool
+}
+
+// File is a stored upload. Key is its key in the blob store.
+type File struct {
+	Checksum string
+	Key      string
+	Size     int64
+}
+
+func New(dir string, blobs blob.Store) *Store {
+	return &Store{
+		dir:   dir,
+		blobs: blobs,
+		busy:  make(map[string]bool),
+	}
+}
+
+// Blobs is the blob store behind s.
+func (s *Store) Blobs() blob.Store {
+	return s.blobs
+}
+
+// Save streams r to disk while hashing it and stores the result under
+// sha256/<checksum>. An existing copy is kept as is. A file over limit
+// bytes is dropped with ErrTooLarge; limit 0 means no limit.
+func (s *Store) Save(ctx context.Context, r io.Reader, limit int64) (*File, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && size > limit {
+		return nil, ErrTooLarge
+	}
+
+	return s.keep(ctx, tmp.Name(), hex.EncodeToString(hash.Sum(nil)), size)
+}
+
+// keep hands the file at src to the blob store under the key of checksum,
+// or drops it if that blob is already stored.
+func (s *Store) keep(ctx context.Context, src, checksum string, size int64) (*File, error) {
+	file := &File{
+		Checksum: checksum,
+		Key:      s.Key(checksum),
+		Size:     size,
+	}
+
+	exists, err := s.blobs.Exists(ctx, file.Key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return file, os.Remove(src)
+	}
+
+	if err := s.blobs.PutFile(ctx, file.Key, src); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Key is the blob key of the file with checksum.
+func (s *Store) Key(checksum string) string {
+	return "sha256/" + checksum
+}
+
+// Fetch makes the blob with key available as a local file for reading.
+// With the local backend that is the blob itself, otherwise a temporary
+// copy. release must be called once the file is no longer needed.
+func (s *Store) Fetch(ctx context.Context, key string) (path string, release func(), err error) {
+	if local, ok := s.blobs.(*blob.Local); ok {
+		path, err := local.Path(key)
+		return path, func() {}, err
+	}
+
+	src, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", nil, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".fetch-*")
+	if err != nil {
+		return "", nil, err
+	}
+	release = func() { os.Remove(tmp.Name()) }
+
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		release()
+		return "", nil, err
+	}
+
+	return tmp.Name(), release, nil
+}
+
+// SaveAs stores r in the blob store under key.
+func (s *Store) SaveAs(ctx context.Context, key string, r io.Reader) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.blobs.PutFile(ctx, key, tmp.Name())
+}
@@ -0,0 +1,47 @@
+package filestore
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrTooLarge means a file is over the size limit of its upload.
+	ErrTooLarge = errors.New("file is too large")
+	// ErrBadName means a client file name is empty or tries to leave its
+	// directory.
+	ErrBadName = errors.New("invalid file name")
+)
+
+// CleanName reduces a file name sent by a client to its base name. Files
+// are stored under server-side names, the client name is only kept for the
+// import history, but it is still checked: names with a ".." segment or
+// control characters are rejected rather than guessed at.
+func CleanName(name string) (string, error) {
+	segments := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '/' || r == '\\'
+	})
+	if len(segments) == 0 {
+		return "", ErrBadName
+	}
+
+	for _, segment := range segments {
+		if segment == ".." {
+			return "", ErrBadName
+		}
+	}
+
+	base := strings.TrimSpace(segments[len(segments)-1])
+	if base == "" || base == "." || len(base) > 255 {
+		return "", ErrBadName
+	}
+
+	for _, r := range base {
+		if unicode.IsControl(r) {
+			return "", ErrBadName
+		}
+	}
+
+	return base, nil
+}
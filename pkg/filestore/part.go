@@ -0,0 +1,163 @@
+package filestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrOffsetMismatch means a chunk does not start where the part ends.
+	ErrOffsetMismatch = errors.New("chunk offset does not match the uploaded size")
+	// ErrPartBusy means another chunk of the part is being written.
+	ErrPartBusy = errors.New("another chunk is being uploaded")
+	// ErrPartTooLarge means a chunk goes past the declared size of the file.
+	ErrPartTooLarge = errors.New("chunk goes past the declared file size")
+)
+
+// A part is a file that arrives in chunks. It lives in <dir>/parts/<id>
+// until it is complete and committed to the store.
+
+// PartPath is where the part id is assembled.
+func (s *Store) PartPath(id string) string {
+	return filepath.Join(s.dir, "parts", id)
+}
+
+// CreatePart starts the empty part id.
+func (s *Store) CreatePart(id string) error {
+	if err := os.MkdirAll(filepath.Join(s.dir, "parts"), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.PartPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// PartSize is how many bytes of the part id have arrived.
+func (s *Store) PartSize(id string) (int64, error) {
+	info, err := os.Stat(s.PartPath(id))
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// WritePart appends the chunk r to the part id. offset must be the current
+// size of the part and the chunk may not take it past size. If r breaks off
+// the bytes received so far are kept, so the client can resume from the
+// returned offset.
+func (s *Store) WritePart(id string, offset, size int64, r io.Reader) (int64, error) {
+	if !s.lock(id) {
+		return 0, ErrPartBusy
+	}
+	defer s.unlock(id)
+
+	file, err := os.OpenFile(s.PartPath(id), os.O_WRONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	current, err := appendPart(file, offset, size, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return current, err
+}
+
+func appendPart(file *os.File, offset, size int64, r io.Reader) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.Size() != offset {
+		return info.Size(), ErrOffsetMismatch
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	written, err := io.Copy(file, io.LimitReader(r, size-offset+1))
+	if offset+written > size {
+		return offset, errors.Join(ErrPartTooLarge, file.Truncate(offset))
+	}
+
+	return offset + written, err
+}
+
+// CommitPart stores a copy of the complete part id. The part itself stays
+// until RemovePart, so an upload whose import job could not be created is
+// committed again on the next try.
+func (s *Store) CommitPart(ctx context.Context, id string) (*File, error) {
+	if !s.lock(id) {
+		return nil, ErrPartBusy
+	}
+	defer s.unlock(id)
+
+	file, err := os.Open(s.PartPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// The blob store takes the file it is given, a hard link hands it the
+	// part without copying it.
+	tmp := filepath.Join(s.dir, ".commit-"+id)
+	os.Remove(tmp)
+	if err := os.Link(s.PartPath(id), tmp); err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	return s.keep(ctx, tmp, hex.EncodeToString(hash.Sum(nil)), size)
+}
+
+// RemovePart drops the part id.
+func (s *Store) RemovePart(id string) error {
+	if !s.lock(id) {
+		return ErrPartBusy
+	}
+	defer s.unlock(id)
+
+	err := os.Remove(s.PartPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *Store) lock(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.busy[id] {
+		return false
+	}
+	s.busy[id] = true
+
+	return true
+}
+
+func (s *Store) unlock(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.busy, id)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"regexp"
+	"time"
+	_ "time/tzdata" // IsValidTimezone must not depend on the host zoneinfo
 )
 
 // IsValidPhone ...
@@ -31,6 +33,60 @@ func IsValidUUID(uuid string) bool {
 	return r.MatchString(uuid)
 }
 
+// IsValidCountryCode checks for an ISO 3166-1 alpha-2 code
+func IsValidCountryCode(code string) bool {
+	r := regexp.MustCompile(`^[A-Z]{2}$`)
+	return r.MatchString(code)
+}
+
+// IsValidContinent ...
+func IsValidContinent(continent string) bool {
+	switch continent {
+	case "AF", "AN", "AS", "EU", "NA", "OC", "SA":
+		return true
+	}
+	return false
+}
+
+// IsValidLatitude ...
+func IsValidLatitude(latitude float64) bool {
+	return latitude >= -90 && latitude <= 90
+}
+
+// IsValidLongitude ...
+func IsValidLongitude(longitude float64) bool {
+	return longitude >= -180 && longitude <= 180
+}
+
+// timestampLayouts are the timestamp formats accepted in import files.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// IsValidTimestamp ...
+func IsValidTimestamp(timestamp string) bool {
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, timestamp); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsValidTimezone ...
+func IsValidTimezone(name string) bool {
+	if name == "" || name == "Local" || len(name) > 24 {
+		return false
+	}
+
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
 func NewNullString(s string) sql.NullString {
 
 	if len(s) == 0 {
@@ -43,8 +99,6 @@ func NewNullString(s string) sql.NullString {
 	}
 }
 
-
-
 type City struct {
 	Guid        string  `json:"guid"`
 	Title       string  `json:"title"`
@@ -57,7 +111,6 @@ type City struct {
 	CountryName string  `json:"country_name"`
 }
 
-
 func ReadJSONFile(filePath string) ([]City, error) {
 	file, err := ioutil.ReadFile(filePath)
 	if err != nil {
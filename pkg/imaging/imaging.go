@@ -0,0 +1,137 @@
+// Package imaging checks uploaded images and scales them down to
+// thumbnails with the standard library only.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"net/http"
+
+	// Decoders for image.Decode.
+	_ "image/gif"
+	_ "image/png"
+)
+
+// MaxPixels bounds the decoded size of an image, so a small file cannot
+// expand into gigabytes of memory.
+const MaxPixels = 40_000_000
+
+// Types maps the accepted content types to the file extension they are
+// stored with.
+var Types = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var ErrUnsupported = errors.New("image must be JPEG, PNG or GIF")
+
+// Size is a thumbnail that fits into Max x Max pixels.
+type Size struct {
+	Name string
+	Max  int
+}
+
+var Sizes = []Size{
+	{Name: "small", Max: 160},
+	{Name: "medium", Max: 640},
+}
+
+// Decode sniffs the content type of data, rejects anything but JPEG, PNG
+// and GIF, checks the dimensions and decodes the image.
+func Decode(data []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := Types[contentType]; !ok {
+		return nil, contentType, ErrUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, contentType, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, contentType, fmt.Errorf("image is %dx%d, at most %d pixels are allowed", config.Width, config.Height, MaxPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, contentType, err
+	}
+
+	return img, contentType, nil
+}
+
+// Thumbnail scales img down to fit into max x max, keeping the aspect
+// ratio, and writes it to w as JPEG. Transparent areas turn white. Images
+// that already fit are only re-encoded.
+func Thumbnail(w io.Writer, img image.Image, max int) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > max || height > max {
+		if width >= height {
+			width, height = max, height*max/width
+		} else {
+			width, height = width*max/height, max
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+
+	return jpeg.Encode(w, scale(src, width, height), &jpeg.Options{Quality: 85})
+}
+
+// scale resizes src to width x height by averaging the source pixels
+// that fall into each target pixel.
+func scale(src *image.RGBA, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, (y+1)*srcHeight/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, (x+1)*srcWidth/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += int(pixel[0])
+					g += int(pixel[1])
+					b += int(pixel[2])
+					a += int(pixel[3])
+					n++
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
@@ -2,13 +2,39 @@ package postgres
 
 import (
 	"database/sql"
-	"encoding/json"
-	"io/ioutil"
 	"ret/api/models"
+	"ret/pkg/dataset"
+	"ret/pkg/helpers"
 
 	"github.com/google/uuid"
+	"github.com/spf13/cast"
 )
 
+var airportImporter = importer{
+	Slug:      models.ImportEntityAirport,
+	Model:     models.Airport{},
+	Required:  []string{"guid", "title", "latitude", "longitude"},
+	FileNames: []string{"airport", "airports", "building", "buildings"},
+	Table: importTable{
+		Name: "buildings",
+		Columns: []string{
+			"guid", "title", "country_id", "city_id", "latitude", "longitude", "radius", "image",
+			"address", "timezone_id", "country", "city", "search_text", "code", "product_count", "gmt",
+			"created_at", "updated_at", "legacy_id",
+		},
+		References: []importReference{
+			{Column: "country_id", Table: "countries", Reason: "country does not exist", Keys: countryKeys, From: []string{"country"}},
+			{Column: "city_id", Table: "cities", Reason: "city does not exist", Keys: cityKeys, From: []string{"city"}},
+			{Column: "timezone_id", Table: "timezone", Reason: "timezone does not exist", Nullify: true, Keys: timezoneKeys},
+		},
+	},
+	Values: airportImportValues,
+	List: func(req models.ExportRequest) (*listQuery, error) {
+		return airportListQuery(req.Airport)
+	},
+	Renamed: map[string]string{"adress": "address"},
+}
+
 type AirportRepo struct {
 	db *sql.DB
 }
@@ -136,7 +162,7 @@ func (c *AirportRepo) GetById(req models.AirportPrimaryKey) (*models.Airport, er
 	}
 
 	return &models.Airport{
-		Guid:           Id.String,
+		Guid:         Id.String,
 		Title:        Title.String,
 		CountryId:    CountryId.String,
 		CityId:       CityId.String,
@@ -170,9 +196,20 @@ func (c *AirportRepo) GetList(req models.GetListAirportRequest) (*models.GetList
 		limit = 10
 	}
 
+	query, err := airportListQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.paginate(offset, limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	page := query.page()
+
 	rows, err := c.db.Query(`
 		SELECT
-			COUNT(*) OVER(),
+			`+query.key()+`,
 			guid,
 			title,
 			country_id,
@@ -191,9 +228,7 @@ func (c *AirportRepo) GetList(req models.GetListAirportRequest) (*models.GetList
 			gmt,
 			created_at,
 			updated_at
-		FROM buildings
-		LIMIT $1 OFFSET $2  
-	`, limit, offset)
+		FROM buildings`+query.sql()+page, query.args...)
 
 	if err != nil {
 		return nil, err
@@ -201,8 +236,10 @@ func (c *AirportRepo) GetList(req models.GetListAirportRequest) (*models.GetList
 
 	defer rows.Close()
 
+	var keys []string
 	for rows.Next() {
 		var (
+			Key          sql.NullString
 			Id           sql.NullString
 			Title        sql.NullString
 			CountryId    sql.NullString
@@ -224,7 +261,7 @@ func (c *AirportRepo) GetList(req models.GetListAirportRequest) (*models.GetList
 		)
 
 		err = rows.Scan(
-			&airports.Count,
+			&Key,
 			&Id,
 			&Title,
 			&CountryId,
@@ -249,7 +286,7 @@ func (c *AirportRepo) GetList(req models.GetListAirportRequest) (*models.GetList
 		}
 
 		airports.Airports = append(airports.Airports, models.Airport{
-			Guid:           Id.String,
+			Guid:         Id.String,
 			Title:        Title.String,
 			CountryId:    CountryId.String,
 			CityId:       CityId.String,
@@ -268,7 +305,20 @@ func (c *AirportRepo) GetList(req models.GetListAirportRequest) (*models.GetList
 			CreatedAt:    CreatedAt.String,
 			UpdatedAt:    UpdatedAt.String,
 		})
+		keys = append(keys, Key.String)
+	}
+
+	n, next, prev := query.cursors(keys, func(i, j int) {
+		airports.Airports[i], airports.Airports[j] = airports.Airports[j], airports.Airports[i]
+	})
+	airports.Airports = airports.Airports[:n]
+	airports.NextCursor, airports.PrevCursor = next, prev
+
+	airports.Count, err = query.count(c.db, "buildings")
+	if err != nil {
+		return nil, err
 	}
+
 	return &airports, nil
 }
 
@@ -311,43 +361,92 @@ func (c *AirportRepo) Delete(req models.AirportPrimaryKey) error {
 	return nil
 }
 
-func (s *AirportRepo) ImportFromFileAirport(filePath string) error {
-	fileContent, err := ioutil.ReadFile(filePath)
+// GetImage returns where the uploaded image of the airport is stored, or
+// sql.ErrNoRows if there is no such airport.
+func (c *AirportRepo) GetImage(req models.AirportPrimaryKey) (*models.AirportImage, error) {
+	var (
+		Key         sql.NullString
+		ContentType sql.NullString
+		UpdatedAt   sql.NullString
+	)
+
+	err := c.db.QueryRow(`
+		SELECT
+			image_key,
+			image_type,
+			image_updated_at
+		FROM buildings
+		WHERE guid = $1
+	`, req.Id).Scan(
+		&Key,
+		&ContentType,
+		&UpdatedAt,
+	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var airports []models.Airport
-	if err := json.Unmarshal(fileContent, &airports); err != nil {
-		return err
-	}
+	return &models.AirportImage{
+		AirportId:   req.Id,
+		Key:         Key.String,
+		ContentType: ContentType.String,
+		UpdatedAt:   UpdatedAt.String,
+	}, nil
+}
 
-	tx, err := s.db.Begin()
+func (c *AirportRepo) UpdateImage(req models.UpdateAirportImage) (*models.Airport, error) {
+	_, err := c.db.Exec(`
+		UPDATE buildings
+		SET
+			image = $2,
+			image_key = $3,
+			image_type = $4,
+			image_updated_at = NOW(),
+			updated_at = NOW()
+		WHERE guid = $1
+	`, req.AirportId, req.Image, helpers.NewNullString(req.Key), helpers.NewNullString(req.ContentType))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	for _, airport := range airports {
-		var count int
-		err := tx.QueryRow("SELECT COUNT(*) FROM countries WHERE guid = $1", airport.CountryId).Scan(&count)
-		if err != nil {
-			return err
-		}
+	return c.GetById(models.AirportPrimaryKey{Id: req.AirportId})
+}
 
-		_, err = tx.Exec(`
-			INSERT INTO buildings (
-				guid, title, country_id, city_id, latitude, longitude, radius, image, address, timezone_id, country, city, search_text, code, product_count, gmt
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
-			airport.Guid, airport.Title, airport.CountryId, airport.CityId, airport.Latitude, airport.Longitude, airport.Radius, airport.Image, airport.Adress, airport.TimezoneId, airport.Country, airport.City, airport.SearchText, airport.Code, airport.ProductCount, airport.Gmt)
-		if err != nil {
-			return err
-		}
+func airportImportValues(record dataset.Record) ([]interface{}, error) {
+	var airport models.Airport
+	if err := dataset.Bind(record, &airport); err != nil {
+		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return err
+	if err := validateAirport(airport); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return []interface{}{
+		airport.Guid, airport.Title, airport.CountryId, airport.CityId, airport.Latitude, airport.Longitude, airport.Radius, airport.Image,
+		airport.Adress, airport.TimezoneId, airport.Country, airport.City, airport.SearchText, airport.Code, airport.ProductCount, airport.Gmt,
+		helpers.NewNullString(airport.CreatedAt), helpers.NewNullString(airport.UpdatedAt), helpers.NewNullString(cast.ToString(record[dataset.LegacyIdColumn])),
+	}, nil
+}
+
+// airportSortFields are the fields airports can be sorted by.
+var airportSortFields = []string{"title", "code", "product_count", "country", "city", "created_at", "updated_at"}
+
+func airportListQuery(req models.GetListAirportRequest) (*listQuery, error) {
+	var query listQuery
+	if err := query.guid("country_id", req.CountryId); err != nil {
+		return nil, err
+	}
+	if err := query.guid("city_id", req.CityId); err != nil {
+		return nil, err
+	}
+	query.equal("code", req.Code)
+	if err := query.between("product_count", req.ProductCountMin, req.ProductCountMax); err != nil {
+		return nil, err
+	}
+
+	if err := query.sort(req.Sort, airportSortFields); err != nil {
+		return nil, err
+	}
+	return &query, nil
 }
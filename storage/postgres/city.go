@@ -2,14 +2,33 @@ package postgres
 
 import (
 	"database/sql"
-	"encoding/json"
-	"io/ioutil"
 	"ret/api/models"
+	"ret/pkg/dataset"
 	"ret/pkg/helpers"
 
 	"github.com/google/uuid"
+	"github.com/spf13/cast"
 )
 
+var cityImporter = importer{
+	Slug:      models.ImportEntityCity,
+	Model:     models.City{},
+	Required:  []string{"guid", "title"},
+	FileNames: []string{"city", "cities"},
+	Table: importTable{
+		Name:    "cities",
+		Columns: []string{"guid", "title", "country_id", "city_code", "latitude", "longitude", "offset", "timezone_id", "country_name", "created_at", "updated_at", "legacy_id"},
+		References: []importReference{
+			{Column: "country_id", Table: "countries", Reason: "country does not exist", Nullify: true, Keys: countryKeys, From: []string{"country_name"}},
+			{Column: "timezone_id", Table: "timezone", Reason: "timezone does not exist", Nullify: true, Keys: timezoneKeys},
+		},
+	},
+	Values: cityImportValues,
+	List: func(req models.ExportRequest) (*listQuery, error) {
+		return cityListQuery(req.City)
+	},
+}
+
 type CityRepo struct {
 	db *sql.DB
 }
@@ -135,9 +154,20 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 		limit = 10
 	}
 
+	query, err := cityListQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.paginate(offset, limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	page := query.page()
+
 	rows, err := c.db.Query(`
 		SELECT
-			COUNT(*) OVER(),
+			`+query.key()+`,
 			"guid",
 			"title",
 			"country_id",
@@ -150,14 +180,16 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 			"created_at",
 			"updated_at"
 		FROM cities
-	`)
+	`+query.sql()+page, query.args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var keys []string
 	for rows.Next() {
 		var (
+			Key         sql.NullString
 			Guid        sql.NullString
 			Title       sql.NullString
 			CountryId   sql.NullString
@@ -172,7 +204,7 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 		)
 
 		err = rows.Scan(
-			&resp.Count,
+			&Key,
 			&Guid,
 			&Title,
 			&CountryId,
@@ -202,6 +234,18 @@ func (c *CityRepo) GetList(req models.GetListCityRequest) (*models.GetListCityRe
 			CreatedAt:   CreatedAt.String,
 			UpdatedAt:   UpdatedAt.String,
 		})
+		keys = append(keys, Key.String)
+	}
+
+	n, next, prev := query.cursors(keys, func(i, j int) {
+		resp.Cities[i], resp.Cities[j] = resp.Cities[j], resp.Cities[i]
+	})
+	resp.Cities = resp.Cities[:n]
+	resp.NextCursor, resp.PrevCursor = next, prev
+
+	resp.Count, err = query.count(c.db, "cities")
+	if err != nil {
+		return nil, err
 	}
 
 	return &resp, nil
@@ -228,50 +272,37 @@ func (c *CityRepo) Delete(req models.CityPrimaryKey) error {
 
 }
 
-func (s *CityRepo) ImportFromFile(filePath string) error {
-	fileContent, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	var cities []models.City
-	if err := json.Unmarshal(fileContent, &cities); err != nil {
-		return err
+func cityImportValues(record dataset.Record) ([]interface{}, error) {
+	var city models.City
+	if err := dataset.Bind(record, &city); err != nil {
+		return nil, err
 	}
 
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
+	if err := validateCity(city); err != nil {
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	for _, city := range cities {
-		var count int
-		err := tx.QueryRow("SELECT COUNT(*) FROM countries WHERE guid = $1", city.CountryId).Scan(&count)
-		if err != nil {
-			return err
-		}
+	return []interface{}{
+		city.Guid, city.Title, city.CountryId, city.CityCode, city.Latitude, city.Longitude, city.Offset, city.TimezoneId, city.CountryName,
+		helpers.NewNullString(city.CreatedAt), helpers.NewNullString(city.UpdatedAt), helpers.NewNullString(cast.ToString(record[dataset.LegacyIdColumn])),
+	}, nil
+}
 
-		var countryID interface{}
-		if count == 0 {
-			countryID = nil
-		} else {
-			countryID = city.CountryId
-		}
+// citySortFields are the fields cities can be sorted by.
+var citySortFields = []string{"title", "city_code", "country_name", "created_at", "updated_at"}
 
-		_, err = tx.Exec(`
-			INSERT INTO cities (
-				guid, title, country_id, city_code, latitude, longitude, "offset", timezone_id, country_name
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-			city.Guid, city.Title, countryID, city.CityCode, city.Latitude, city.Longitude, city.Offset, city.TimezoneId, city.CountryName)
-		if err != nil {
-			return err
-		}
+func cityListQuery(req models.GetListCityRequest) (*listQuery, error) {
+	var query listQuery
+	if err := query.guid("country_id", req.CountryId); err != nil {
+		return nil, err
 	}
-
-	if err := tx.Commit(); err != nil {
-		return err
+	if err := query.guid("timezone_id", req.TimezoneId); err != nil {
+		return nil, err
 	}
+	query.prefix("title", req.Title)
 
-	return nil
+	if err := query.sort(req.Sort, citySortFields); err != nil {
+		return nil, err
+	}
+	return &query, nil
 }
@@ -2,13 +2,29 @@ package postgres
 
 import (
 	"database/sql"
-	"encoding/json"
-	"io/ioutil"
 	"ret/api/models"
+	"ret/pkg/dataset"
+	"ret/pkg/helpers"
 
 	"github.com/google/uuid"
+	"github.com/spf13/cast"
 )
 
+var countryImporter = importer{
+	Slug:      models.ImportEntityCountry,
+	Model:     models.Country{},
+	Required:  []string{"guid", "title", "code", "continent"},
+	FileNames: []string{"country", "countries"},
+	Table: importTable{
+		Name:    "countries",
+		Columns: []string{"guid", "title", "code", "continent", "created_at", "updated_at", "legacy_id"},
+	},
+	Values: countryImportValues,
+	List: func(req models.ExportRequest) (*listQuery, error) {
+		return countryListQuery(req.Country)
+	},
+}
+
 type CountryRepo struct {
 	db *sql.DB
 }
@@ -77,14 +93,27 @@ func (c *CountryRepo) GetList(req models.GetListCountryRequest) (*models.GetList
 		limit = 10
 	}
 
-	rows, err := c.db.Query(`SELECT COUNT(*) OVER(), guid, title, code, continent, created_at, updated_at FROM countries`)
+	query, err := countryListQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.paginate(offset, limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	page := query.page()
+
+	rows, err := c.db.Query(`SELECT `+query.key()+`, guid, title, code, continent, created_at, updated_at FROM countries`+query.sql()+page, query.args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var keys []string
 	for rows.Next() {
 		var (
+			Key       sql.NullString
 			Guid      sql.NullString
 			Title     sql.NullString
 			Code      sql.NullString
@@ -94,7 +123,7 @@ func (c *CountryRepo) GetList(req models.GetListCountryRequest) (*models.GetList
 		)
 
 		err = rows.Scan(
-			&countries.Count,
+			&Key,
 			&Guid,
 			&Title,
 			&Code,
@@ -113,6 +142,18 @@ func (c *CountryRepo) GetList(req models.GetListCountryRequest) (*models.GetList
 			CreatedAt: CreatedAt.String,
 			UpdatedAt: UpdatedAt.String,
 		})
+		keys = append(keys, Key.String)
+	}
+
+	n, next, prev := query.cursors(keys, func(i, j int) {
+		countries.Countries[i], countries.Countries[j] = countries.Countries[j], countries.Countries[i]
+	})
+	countries.Countries = countries.Countries[:n]
+	countries.NextCursor, countries.PrevCursor = next, prev
+
+	countries.Count, err = query.count(c.db, "countries")
+	if err != nil {
+		return nil, err
 	}
 
 	return &countries, nil
@@ -136,38 +177,32 @@ func (c *CountryRepo) Delete(req models.CountryPrimaryKey) error {
 	return nil
 }
 
-
-
-func (s *CountryRepo) ImportFromFileCountry(filePath string) error {
-	fileContent, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return err
+func countryImportValues(record dataset.Record) ([]interface{}, error) {
+	var country models.Country
+	if err := dataset.Bind(record, &country); err != nil {
+		return nil, err
 	}
 
-	var countries []models.Country
-	if err := json.Unmarshal(fileContent, &countries); err != nil {
-		return err
+	if err := validateCountry(country); err != nil {
+		return nil, err
 	}
 
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
+	return []interface{}{
+		country.Guid, country.Title, country.Code, country.Continent,
+		helpers.NewNullString(country.CreatedAt), helpers.NewNullString(country.UpdatedAt), helpers.NewNullString(cast.ToString(record[dataset.LegacyIdColumn])),
+	}, nil
+}
 
-	for _, country := range countries {
-		_, err := tx.Exec(
-			`INSERT INTO countries (guid, title, code, continent) VALUES ($1, $2, $3, $4)`,
-			country.Guid, country.Title, country.Code, country.Continent,
-		)
-		if err != nil {
-			return err
-		}
-	}
+// countrySortFields are the fields countries can be sorted by.
+var countrySortFields = []string{"title", "code", "continent", "created_at", "updated_at"}
 
-	if err := tx.Commit(); err != nil {
-		return err
-	}
+func countryListQuery(req models.GetListCountryRequest) (*listQuery, error) {
+	var query listQuery
+	query.equal("continent", req.Continent)
+	query.equal("code", req.Code)
 
-	return nil
+	if err := query.sort(req.Sort, countrySortFields); err != nil {
+		return nil, err
+	}
+	return &query, nil
 }
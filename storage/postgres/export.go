@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"ret/api/models"
+	"ret/pkg/dataset"
+)
+
+// Export calls each with every row of the entity that passes the filters of
+// the request, keyed by the fields of its import schema, so the records can
+// be written to a file the importer takes back. Rows are passed on as the
+// driver reads them, never held together.
+func (r *ImportRepo) Export(ctx context.Context, req models.ExportRequest, each func(dataset.Record) error) error {
+	imp, ok := importers[req.Entity]
+	if !ok {
+		return fmt.Errorf("unknown export entity: %s", req.Entity)
+	}
+
+	fields := imp.schema().Fields
+
+	query := &listQuery{}
+	if imp.List != nil {
+		var err error
+		if query, err = imp.List(req); err != nil {
+			return err
+		}
+	} else if err := query.sort("", nil); err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT to_jsonb(t)::text FROM `+imp.Table.Name+` t`+query.sql(), query.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var image string
+		if err := rows.Scan(&image); err != nil {
+			return err
+		}
+
+		var row map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader([]byte(image)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&row); err != nil {
+			return err
+		}
+
+		record := make(dataset.Record, len(fields))
+		for _, field := range fields {
+			record[field.Name] = row[imp.column(field.Name)]
+		}
+
+		if err := each(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,582 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"ret/api/models"
+	"ret/pkg/dataset"
+	"ret/storage"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/spf13/cast"
+)
+
+// pqKeyDetail picks the column out of details like
+// "Key (country_id)=(...) is not present in table "countries"."
+var pqKeyDetail = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// importTable describes where an entity is imported to. The first column
+// must be guid, it is the natural key for every conflict strategy.
+type importTable struct {
+	Name       string
+	Columns    []string
+	References []importReference
+}
+
+// importReference is a column holding the guid of a row in another table.
+// A value that is not a guid is looked up by the natural Keys of that table,
+// an empty one is taken from the first From column that has a value, e.g.
+// the country name of an airport. What happens to values matching no row is
+// up to ImportRequest.OnMissing; by default they reject the row, or become
+// NULL if Nullify is set, and a From value that matches nothing is ignored.
+// Empty values become NULL if Nullify is set.
+type importReference struct {
+	Column  string
+	Table   string
+	Reason  string
+	Nullify bool
+	Keys    []importKey
+	From    []string
+}
+
+// importBatchSize bounds how many records are held in memory at a time.
+const importBatchSize = 500
+
+// importValues binds one record of an import file, validates it and returns
+// its values in the order of importTable.Columns.
+type importValues func(record dataset.Record) ([]interface{}, error)
+
+// readerOptions are the options the file of req is read with.
+func readerOptions(req models.ImportRequest) dataset.Options {
+	opts := dataset.Options{Aliases: req.Aliases, KeepLegacyId: req.KeepLegacyId, Sheet: req.Sheet}
+	if req.Mapping != nil {
+		opts.Mapping = &dataset.Mapping{
+			Rename:     req.Mapping.Rename,
+			Drop:       req.Mapping.Drop,
+			Transforms: req.Mapping.Transforms,
+			Defaults:   req.Mapping.Defaults,
+		}
+	}
+
+	return opts
+}
+
+// importFile imports the file of req in a single transaction, which a dry
+// run rolls back.
+func importFile(ctx context.Context, db *sql.DB, table importTable, req models.ImportRequest, values importValues) (*models.ImportResult, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := importTx(ctx, tx, table, req, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DryRun {
+		return result, tx.Rollback()
+	}
+
+	if err := checkCancelled(ctx, tx, req.BatchId); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkCancelled fails the import if its job was cancelled while it ran.
+// The job row stays locked until the commit, so a cancel cannot land in
+// between. A batch without a job is not checked.
+func checkCancelled(ctx context.Context, tx *sql.Tx, batchId string) error {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM import_jobs WHERE guid::text = $1 FOR UPDATE`, batchId).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if status == models.ImportJobCancelled {
+		return storage.ErrImportCancelled
+	}
+
+	return nil
+}
+
+// importTx runs the loader chosen by req inside tx.
+func importTx(ctx context.Context, tx *sql.Tx, table importTable, req models.ImportRequest, values importValues) (*models.ImportResult, error) {
+	switch req.Loader {
+	case models.ImportLoaderRow, "":
+		return importRows(ctx, tx, table, req, values)
+	case models.ImportLoaderCopy:
+		return copyRows(ctx, tx, table, req, values)
+	}
+
+	return nil, fmt.Errorf("unknown import loader: %s", req.Loader)
+}
+
+// importRows streams the file of req in batches. In strict mode the first
+// failing row aborts the import; in partial mode every row runs under its
+// own savepoint, failing rows are rolled back and reported and the rest is
+// kept. A dry run behaves like partial mode.
+func importRows(ctx context.Context, tx *sql.Tx, table importTable, req models.ImportRequest, values importValues) (*models.ImportResult, error) {
+	query, err := importQuery(table, req.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := dataset.Open(req.FilePath, req.Format, readerOptions(req))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var (
+		result     = models.ImportResult{DryRun: req.DryRun}
+		partial    = req.Mode == models.ImportModePartial || req.DryRun
+		replaceAll = req.Strategy == models.ImportStrategyReplaceAll
+		resolver   = newImportResolver(tx, table, req)
+		batch      = make([]dataset.Record, 0, importBatchSize)
+		guids      = make([]string, 0, importBatchSize)
+		eof        = false
+	)
+
+	// Guids seen by replace-all are kept in the database, not in memory.
+	if replaceAll {
+		if err := createSeenTable(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	for !eof {
+		batch, guids = batch[:0], guids[:0]
+		for len(batch) < importBatchSize {
+			record, err := reader.Read()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			batch = append(batch, record)
+		}
+
+		for _, record := range batch {
+			index := result.RowsProcessed
+			guid := cast.ToString(record["guid"])
+			guids = append(guids, guid)
+			result.RowsProcessed++
+
+			if partial {
+				if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+					return nil, err
+				}
+			}
+
+			action, err := writeImportRow(ctx, tx, resolver, query, values, record, req.BatchId)
+			if err != nil {
+				result.AddError(newImportRowError(index, guid, err))
+				req.Progress(result)
+
+				if !partial || ctx.Err() != nil {
+					return nil, err
+				}
+
+				if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); err != nil {
+					return nil, err
+				}
+				resolver.forget()
+				result.StubsCreated = resolver.stubs
+				continue
+			}
+			result.StubsCreated = resolver.stubs
+
+			if partial {
+				if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+					return nil, err
+				}
+			}
+
+			switch action {
+			case importInserted:
+				result.RowsInserted++
+			case importUpdated:
+				result.RowsUpdated++
+			case importSkipped:
+				result.RowsSkipped++
+			}
+			req.Progress(result)
+		}
+
+		if replaceAll {
+			if err := markSeen(ctx, tx, guids); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if replaceAll {
+		result.RowsDeleted, err = deleteUnseen(ctx, tx, table, req)
+		if err != nil {
+			return nil, err
+		}
+		req.Progress(result)
+	}
+
+	return &result, nil
+}
+
+func (t importTable) column(name string) int {
+	for i, column := range t.Columns {
+		if column == name {
+			return i
+		}
+	}
+
+	panic("import: unknown column " + name + " of " + t.Name)
+}
+
+const (
+	importInserted = "inserted"
+	importUpdated  = "updated"
+	importSkipped  = "skipped"
+)
+
+// importQuery builds the statement a strategy writes one row with. It takes
+// the values of table.Columns followed by the batch id, tags the row with the
+// batch and records its before-image. It returns whether the row was inserted
+// (true) or updated (false); no row at all means the row was skipped.
+func importQuery(table importTable, strategy string) (string, error) {
+	placeholders := make([]string, 0, len(table.Columns))
+	for i, column := range table.Columns {
+		placeholders = append(placeholders, importValue(column, "$"+strconv.Itoa(i+1)))
+	}
+	batch := "$" + strconv.Itoa(len(table.Columns)+1) + "::uuid"
+
+	conflict, err := importConflict(table, strategy, func(column string) string {
+		return "$" + strconv.Itoa(table.column(column)+1)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return `
+		WITH before AS (
+			SELECT to_jsonb(t) AS image FROM ` + table.Name + ` t WHERE t.guid = $1
+		), written AS (
+			INSERT INTO ` + table.Name + ` (` + table.columnList("") + `, "import_batch_id")
+			VALUES (` + strings.Join(placeholders, ", ") + `, ` + batch + `)` + conflict + `
+		), versions AS (
+			` + importVersions(table, batch, `written w`, `(SELECT image FROM before)`) + `
+		)
+		SELECT inserted FROM written`, nil
+}
+
+// importVersions records the before-image of the rows in from, which must
+// have the guid and inserted columns of importConflict. Only the first image
+// of a row in a batch is kept.
+func importVersions(table importTable, batch, from, image string) string {
+	return `INSERT INTO import_row_versions (batch_id, table_name, guid, action, before)
+			SELECT ` + batch + `, '` + table.Name + `', w.guid::text, CASE WHEN w.inserted THEN 'inserted' ELSE 'updated' END, ` + image + `
+			FROM ` + from + `
+			ON CONFLICT DO NOTHING`
+}
+
+// importValue wraps the SQL expression of an imported value with the default
+// of its column. Timestamps missing from the file are set to NOW().
+func importValue(column, value string) string {
+	switch column {
+	case "created_at", "updated_at":
+		return `COALESCE(` + value + `::timestamp, NOW())`
+	}
+
+	return value
+}
+
+// importConflict returns the ON CONFLICT and RETURNING clauses of strategy.
+// The returned "inserted" column is false for updated rows. source gives the
+// expression of the value the file had for a column, before any default, so
+// a missing created_at or legacy_id keeps the stored one.
+func importConflict(table importTable, strategy string, source func(column string) string) (string, error) {
+	switch strategy {
+	case models.ImportStrategyInsertOnly, "":
+		return ` RETURNING "guid", true AS inserted`, nil
+	case models.ImportStrategySkipExisting:
+		return ` ON CONFLICT (guid) DO NOTHING RETURNING "guid", true AS inserted`, nil
+	case models.ImportStrategyUpsert, models.ImportStrategyReplaceAll:
+		updates := make([]string, 0, len(table.Columns))
+		for _, column := range table.Columns[1:] {
+			switch column {
+			case "created_at", "legacy_id":
+				updates = append(updates, `"`+column+`" = COALESCE(`+source(column)+`, `+table.Name+`."`+column+`")`)
+			case "updated_at":
+				updates = append(updates, `"`+column+`" = `+importValue(column, source(column)))
+			default:
+				updates = append(updates, `"`+column+`" = EXCLUDED."`+column+`"`)
+			}
+		}
+		updates = append(updates, `"import_batch_id" = EXCLUDED."import_batch_id"`)
+
+		return ` ON CONFLICT (guid) DO UPDATE SET ` + strings.Join(updates, ", ") + ` RETURNING "guid", (xmax = 0) AS inserted`, nil
+	}
+
+	return "", fmt.Errorf("unknown import strategy: %s", strategy)
+}
+
+// columnList quotes the columns of t, optionally prefixed with a table alias.
+func (t importTable) columnList(alias string) string {
+	columns := make([]string, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		if alias != "" {
+			column = alias + `."` + column + `"`
+		} else {
+			column = `"` + column + `"`
+		}
+		columns = append(columns, column)
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// createSeenTable prepares the table replace-all remembers imported guids in.
+// It is emptied for every file imported in the transaction.
+func createSeenTable(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TEMP TABLE IF NOT EXISTS import_seen (guid TEXT PRIMARY KEY) ON COMMIT DROP`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `TRUNCATE import_seen`)
+	return err
+}
+
+func markSeen(ctx context.Context, tx *sql.Tx, guids []string) error {
+	if len(guids) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO import_seen SELECT unnest($1::text[]) ON CONFLICT DO NOTHING`, pq.StringArray(guids))
+	return err
+}
+
+// deleteUnseen removes the rows of table that were not in the file and keeps
+// them as before-images of the batch. Rows that failed keep their previous
+// version because they are marked seen too. The rows of other tables the
+// deletes cascade to are deleted in the same statement, so their images are
+// kept as well and a rollback restores them; the count includes them. A dry
+// run only counts the rows, see countUnseen.
+func deleteUnseen(ctx context.Context, tx *sql.Tx, table importTable, req models.ImportRequest) (int, error) {
+	cascades, err := cascadesOf(ctx, tx, table.Name, map[string]bool{table.Name: true})
+	if err != nil {
+		return 0, err
+	}
+	if req.DryRun {
+		return countUnseen(ctx, tx, table, cascades)
+	}
+
+	deletes := []string{`deleted AS (
+			DELETE FROM ` + table.Name + ` t WHERE NOT EXISTS (SELECT 1 FROM import_seen s WHERE s.guid = t.guid::text) RETURNING t.*
+		)`}
+	images := []string{`SELECT $1::uuid, '` + table.Name + `', d.guid::text, 'deleted', to_jsonb(d) FROM deleted d`}
+	counts := []string{`(SELECT COUNT(*) FROM deleted)`}
+
+	for i, cascade := range cascades {
+		name := "cascaded" + strconv.Itoa(i)
+		parent := "deleted"
+		if cascade.parent >= 0 {
+			parent = "cascaded" + strconv.Itoa(cascade.parent)
+		}
+
+		deletes = append(deletes, name+` AS (
+			DELETE FROM `+cascade.table+` c USING `+parent+` p WHERE c."`+cascade.column+`"::text = p."`+cascade.references+`"::text RETURNING c.*
+		)`)
+		images = append(images, `SELECT $1::uuid, '`+cascade.table+`', c.guid::text, 'deleted', to_jsonb(c) FROM `+name+` c`)
+		counts = append(counts, `(SELECT COUNT(*) FROM `+name+`)`)
+	}
+
+	var deleted int
+	err = tx.QueryRowContext(ctx, `
+		WITH `+strings.Join(deletes, ", ")+`, versions AS (
+			INSERT INTO import_row_versions (batch_id, table_name, guid, action, before)
+			`+strings.Join(images, " UNION ALL ")+`
+			ON CONFLICT DO NOTHING
+		)
+		SELECT `+strings.Join(counts, " + "), req.BatchId).Scan(&deleted)
+
+	return deleted, err
+}
+
+// countUnseen counts the rows deleteUnseen would delete, cascades included,
+// without locking them or firing their triggers.
+func countUnseen(ctx context.Context, tx *sql.Tx, table importTable, cascades []importCascade) (int, error) {
+	selects := []string{`unseen AS (
+			SELECT t.* FROM ` + table.Name + ` t WHERE NOT EXISTS (SELECT 1 FROM import_seen s WHERE s.guid = t.guid::text)
+		)`}
+	counts := []string{`(SELECT COUNT(*) FROM unseen)`}
+
+	for i, cascade := range cascades {
+		name := "cascaded" + strconv.Itoa(i)
+		parent := "unseen"
+		if cascade.parent >= 0 {
+			parent = "cascaded" + strconv.Itoa(cascade.parent)
+		}
+
+		selects = append(selects, name+` AS (
+			SELECT c.* FROM `+cascade.table+` c WHERE EXISTS (SELECT 1 FROM `+parent+` p WHERE c."`+cascade.column+`"::text = p."`+cascade.references+`"::text)
+		)`)
+		counts = append(counts, `(SELECT COUNT(*) FROM `+name+`)`)
+	}
+
+	var unseen int
+	err := tx.QueryRowContext(ctx, `WITH `+strings.Join(selects, ", ")+` SELECT `+strings.Join(counts, " + ")).Scan(&unseen)
+
+	return unseen, err
+}
+
+// importCascade is a table whose rows are deleted with the rows of another
+// one by an ON DELETE CASCADE foreign key. parent is the cascade of the
+// table referenced, -1 for the table deleted from.
+type importCascade struct {
+	table      string
+	column     string
+	references string
+	parent     int
+}
+
+// cascadesOf lists the cascades of table, parents before children. Only
+// import tables can be rolled back, a cascade into any other table fails.
+func cascadesOf(ctx context.Context, tx *sql.Tx, table string, seen map[string]bool) ([]importCascade, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT child.relname, ca.attname, pa.attname
+		FROM pg_constraint c
+		JOIN pg_class child ON child.oid = c.conrelid
+		JOIN pg_attribute ca ON ca.attrelid = c.conrelid AND ca.attnum = c.conkey[1]
+		JOIN pg_attribute pa ON pa.attrelid = c.confrelid AND pa.attnum = c.confkey[1]
+		WHERE c.contype = 'f' AND c.confdeltype = 'c' AND c.confrelid = to_regclass($1) AND cardinality(c.conkey) = 1
+		ORDER BY child.relname
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var direct []importCascade
+	for rows.Next() {
+		cascade := importCascade{parent: -1}
+		if err := rows.Scan(&cascade.table, &cascade.column, &cascade.references); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		direct = append(direct, cascade)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var cascades []importCascade
+	for _, cascade := range direct {
+		if !isImportTable(cascade.table) {
+			return nil, fmt.Errorf("replace-all would delete rows of %s referencing %s, which cannot be rolled back", cascade.table, table)
+		}
+		if seen[cascade.table] {
+			continue
+		}
+		seen[cascade.table] = true
+
+		index := len(cascades)
+		cascades = append(cascades, cascade)
+
+		children, err := cascadesOf(ctx, tx, cascade.table, seen)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			if child.parent < 0 {
+				child.parent = index
+			} else {
+				child.parent += index + 1
+			}
+			cascades = append(cascades, child)
+		}
+	}
+
+	return cascades, nil
+}
+
+func isImportTable(name string) bool {
+	for _, imp := range importers {
+		if imp.Table.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeImportRow(ctx context.Context, tx *sql.Tx, resolver *importResolver, query string, values importValues, record dataset.Record, batchId string) (string, error) {
+	args, err := values(record)
+	if err != nil {
+		return "", err
+	}
+
+	if err := resolver.resolve(ctx, args); err != nil {
+		return "", err
+	}
+
+	var inserted bool
+	err = tx.QueryRowContext(ctx, query, append(args, batchId)...).Scan(&inserted)
+	switch {
+	case err == sql.ErrNoRows:
+		return importSkipped, nil
+	case err != nil:
+		return "", err
+	case inserted:
+		return importInserted, nil
+	}
+
+	return importUpdated, nil
+}
+
+func newImportRowError(index int, guid string, err error) models.ImportRowError {
+	rowErr := models.ImportRowError{
+		Index:  index,
+		Guid:   guid,
+		Reason: err.Error(),
+	}
+
+	var fieldErr *fieldError
+	var bindErr *dataset.FieldError
+	var pqErr *pq.Error
+	switch {
+	case errors.As(err, &fieldErr):
+		rowErr.Field = fieldErr.field
+		rowErr.Reason = fieldErr.reason
+	case errors.As(err, &bindErr):
+		rowErr.Field = bindErr.Field
+		rowErr.Reason = bindErr.Reason
+	case errors.As(err, &pqErr):
+		rowErr.Field = pqErr.Column
+		if match := pqKeyDetail.FindStringSubmatch(pqErr.Detail); rowErr.Field == "" && match != nil {
+			rowErr.Field = match[1]
+		}
+		if pqErr.Detail != "" {
+			rowErr.Reason = pqErr.Message + ": " + pqErr.Detail
+		}
+	}
+
+	return rowErr
+}
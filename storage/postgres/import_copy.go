@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"ret/api/models"
+	"ret/pkg/dataset"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/spf13/cast"
+)
+
+// copyRows is the bulk loader. Records are bound and validated in Go,
+// streamed into a staging table with COPY FROM STDIN and merged into the
+// target table with a handful of set based statements.
+//
+// Rows rejected by validation, unknown references, duplicate guids or an
+// insert-only conflict are reported like in importRows; references are
+// resolved before the rows are staged. Any other database error aborts the
+// import even in partial mode, because neither COPY nor the merge can tell
+// which row caused it.
+func copyRows(ctx context.Context, tx *sql.Tx, table importTable, req models.ImportRequest, values importValues) (*models.ImportResult, error) {
+	// created_at of existing rows is copied into the staging table before
+	// the merge, so EXCLUDED always carries the value to keep.
+	conflict, err := importConflict(table, req.Strategy, func(column string) string {
+		return `EXCLUDED."` + column + `"`
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := dataset.Open(req.FilePath, req.Format, readerOptions(req))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var (
+		result     = models.ImportResult{DryRun: req.DryRun}
+		partial    = req.Mode == models.ImportModePartial || req.DryRun
+		replaceAll = req.Strategy == models.ImportStrategyReplaceAll
+	)
+
+	_, err = tx.ExecContext(ctx, `DROP TABLE IF EXISTS import_staging`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `CREATE TEMP TABLE import_staging ON COMMIT DROP AS SELECT `+table.columnList("")+` FROM `+table.Name+` WITH NO DATA`)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `ALTER TABLE import_staging ADD COLUMN import_index INT`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Guids of rejected rows are kept in the database batch by batch, like
+	// importRows does, so a bad file does not pile them up in memory.
+	if replaceAll {
+		if err := createSeenTable(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	err = copyStaging(ctx, tx, table, req, values, reader, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if replaceAll {
+		_, err := tx.ExecContext(ctx, `INSERT INTO import_seen SELECT guid::text FROM import_staging ON CONFLICT DO NOTHING`)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reject := func(field, reason, query string) error {
+		rows, err := tx.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var count int
+		for rows.Next() {
+			var (
+				index int
+				guid  sql.NullString
+			)
+			if err := rows.Scan(&index, &guid); err != nil {
+				return err
+			}
+
+			count++
+			result.AddError(models.ImportRowError{Index: index, Guid: guid.String, Field: field, Reason: reason})
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		req.Progress(result)
+		if count > 0 && !partial {
+			return &fieldError{field, reason}
+		}
+
+		return nil
+	}
+
+	err = reject("guid", "duplicate guid in the file", `
+		DELETE FROM import_staging a
+		USING import_staging b
+		WHERE a.guid = b.guid AND a.import_index > b.import_index
+		RETURNING a.import_index, a.guid::text`)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Strategy == models.ImportStrategyInsertOnly || req.Strategy == "" {
+		err := reject("guid", "already exists", `
+			DELETE FROM import_staging s
+			WHERE EXISTS (SELECT 1 FROM `+table.Name+` t WHERE t.guid::text = s.guid::text)
+			RETURNING s.import_index, s.guid::text`)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE import_staging s SET created_at = t.created_at
+		FROM `+table.Name+` t
+		WHERE s.created_at IS NULL AND t.guid::text = s.guid::text`)
+	if err != nil {
+		return nil, err
+	}
+
+	selects := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		selects = append(selects, importValue(column, `s."`+column+`"`))
+	}
+
+	var staged int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM import_staging`).Scan(&staged)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		WITH before AS (
+			SELECT t.guid::text AS guid, to_jsonb(t) AS image FROM `+table.Name+` t JOIN import_staging s ON s.guid = t.guid
+		), merged AS (
+			INSERT INTO `+table.Name+` (`+table.columnList("")+`, "import_batch_id")
+			SELECT `+strings.Join(selects, ", ")+`, $1::uuid FROM import_staging s ORDER BY s.import_index`+conflict+`
+		), versions AS (
+			`+importVersions(table, "$1::uuid", `merged w LEFT JOIN before b ON b.guid = w.guid::text`, `b.image`)+`
+		)
+		SELECT COUNT(*) FILTER (WHERE inserted), COUNT(*) FILTER (WHERE NOT inserted) FROM merged
+	`, req.BatchId).Scan(&result.RowsInserted, &result.RowsUpdated)
+	if err != nil {
+		return nil, err
+	}
+	result.RowsSkipped = staged - result.RowsInserted - result.RowsUpdated
+
+	if replaceAll {
+		result.RowsDeleted, err = deleteUnseen(ctx, tx, table, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req.Progress(result)
+
+	return &result, nil
+}
+
+// copyBatchSize is how many rows go into one COPY. References are resolved
+// between two of them, nothing else can run on the connection while a COPY
+// is open.
+const copyBatchSize = 5000
+
+// copyStaging streams every valid record into import_staging. Guids of rows
+// that failed validation are marked seen for replace-all after each batch.
+func copyStaging(ctx context.Context, tx *sql.Tx, table importTable, req models.ImportRequest, values importValues, reader dataset.Reader, result *models.ImportResult) error {
+	var (
+		partial    = req.Mode == models.ImportModePartial || req.DryRun
+		replaceAll = req.Strategy == models.ImportStrategyReplaceAll
+		resolver   = newImportResolver(tx, table, req)
+		columns    = append(append([]string{}, table.Columns...), "import_index")
+		rows       = make([][]interface{}, 0, copyBatchSize)
+		rejected   = make([]string, 0, copyBatchSize)
+		eof        = false
+	)
+
+	for !eof {
+		rows, rejected = rows[:0], rejected[:0]
+		for len(rows) < copyBatchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			record, err := reader.Read()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			index := result.RowsProcessed
+			guid := cast.ToString(record["guid"])
+			result.RowsProcessed++
+
+			args, err := values(record)
+			if err == nil {
+				err = resolver.resolve(ctx, args)
+				result.StubsCreated = resolver.stubs
+			}
+			if err != nil {
+				result.AddError(newImportRowError(index, guid, err))
+				req.Progress(*result)
+
+				if !partial {
+					return err
+				}
+				rejected = append(rejected, guid)
+				continue
+			}
+
+			rows = append(rows, append(args, index))
+			req.Progress(*result)
+		}
+
+		if err := copyBatch(ctx, tx, columns, rows); err != nil {
+			return err
+		}
+
+		if replaceAll {
+			if err := markSeen(ctx, tx, rejected); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// copyBatch copies rows into import_staging.
+func copyBatch(ctx context.Context, tx *sql.Tx, columns []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("import_staging", columns...))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return err
+		}
+	}
+
+	// An Exec without arguments flushes the COPY buffer.
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
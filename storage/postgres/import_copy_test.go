@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"ret/api/models"
+	"ret/pkg/dataset"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// benchRows is the size of the file both loaders import.
+const benchRows = 20000
+
+// BenchmarkImportLoaders imports the same file of timezones with the row
+// and the COPY loader. It needs a migrated database, given as a lib/pq
+// connection string in IMPORT_BENCH_POSTGRES, e.g.
+//
+//	IMPORT_BENCH_POSTGRES="host=localhost user=postgres dbname=ret sslmode=disable" \
+//		go test ./storage/postgres -run '^$' -bench ImportLoaders
+//
+// Every import runs in a transaction that is rolled back, so the database
+// is left as it was.
+func BenchmarkImportLoaders(b *testing.B) {
+	dsn := os.Getenv("IMPORT_BENCH_POSTGRES")
+	if dsn == "" {
+		b.Skip("IMPORT_BENCH_POSTGRES is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(b.TempDir(), "timezones.ndjson")
+	file, err := os.Create(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w := bufio.NewWriter(file)
+	for i := 0; i < benchRows; i++ {
+		fmt.Fprintf(w, `{"guid":%q,"title":"Asia/Tashkent"}`+"\n", uuid.New().String())
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+	file.Close()
+
+	for _, loader := range []string{models.ImportLoaderRow, models.ImportLoaderCopy} {
+		b.Run(loader, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tx, err := db.BeginTx(context.Background(), nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				result, err := importTx(context.Background(), tx, timezoneImporter.Table, models.ImportRequest{
+					FilePath: path,
+					Format:   dataset.FormatNDJSON,
+					Mode:     models.ImportModeStrict,
+					Strategy: models.ImportStrategyUpsert,
+					Loader:   loader,
+					BatchId:  uuid.New().String(),
+				}, timezoneImporter.Values)
+				tx.Rollback()
+				if err != nil {
+					b.Fatal(err)
+				}
+				if result.RowsInserted+result.RowsUpdated != benchRows {
+					b.Fatalf("imported %d rows, want %d", result.RowsInserted+result.RowsUpdated, benchRows)
+				}
+			}
+
+			b.ReportMetric(float64(benchRows*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package postgres
+
+import (
+	"database/sql"
+	"ret/api/models"
+	"ret/pkg/helpers"
+
+	"github.com/google/uuid"
+)
+
+type ImportJobRepo struct {
+	db *sql.DB
+}
+
+func NewImportJobRepo(db *sql.DB) *ImportJobRepo {
+	return &ImportJobRepo{
+		db: db,
+	}
+}
+
+func (r *ImportJobRepo) Create(req models.CreateImportJob) (*models.ImportJob, error) {
+	var id string
+
+	err := r.db.QueryRow(`
+		INSERT INTO import_jobs(
+			guid,
+			entity,
+			file_name,
+			file_path,
+			checksum,
+			uploaded_by,
+			idempotency_key,
+			format,
+			mode,
+			strategy,
+			loader,
+			mapping_profile_id,
+			on_missing,
+			sheet,
+			status,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NOW()) RETURNING guid`,
+		uuid.New().String(),
+		req.Entity,
+		req.FileName,
+		req.FilePath,
+		helpers.NewNullString(req.Checksum),
+		helpers.NewNullString(req.UploadedBy),
+		helpers.NewNullString(req.IdempotencyKey),
+		req.Format,
+		req.Mode,
+		req.Strategy,
+		req.Loader,
+		helpers.NewNullString(req.MappingProfileId),
+		helpers.NewNullString(req.OnMissing),
+		helpers.NewNullString(req.Sheet),
+		models.ImportJobQueued,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetById(models.ImportJobPrimaryKey{Id: id})
+}
+
+const importJobColumns = `
+	guid,
+	entity,
+	file_name,
+	file_path,
+	checksum,
+	uploaded_by,
+	idempotency_key,
+	format,
+	mode,
+	strategy,
+	loader,
+	mapping_profile_id,
+	on_missing,
+	sheet,
+	status,
+	rows_processed,
+	rows_inserted,
+	rows_updated,
+	rows_skipped,
+	rows_deleted,
+	rows_failed,
+	error,
+	started_at,
+	finished_at,
+	rolled_back_at,
+	created_at,
+	updated_at`
+
+// scanImportJob reads a row selected with importJobColumns.
+func scanImportJob(row interface{ Scan(...interface{}) error }) (*models.ImportJob, error) {
+	var (
+		Guid           sql.NullString
+		Entity         sql.NullString
+		FileName       sql.NullString
+		FilePath       sql.NullString
+		Checksum       sql.NullString
+		UploadedBy     sql.NullString
+		IdempotencyKey sql.NullString
+		Format         sql.NullString
+		Mode           sql.NullString
+		Strategy       sql.NullString
+		Loader         sql.NullString
+		MappingProfile sql.NullString
+		OnMissing      sql.NullString
+		Sheet          sql.NullString
+		Status         sql.NullString
+		RowsProcessed  sql.NullInt64
+		RowsInserted   sql.NullInt64
+		RowsUpdated    sql.NullInt64
+		RowsSkipped    sql.NullInt64
+		RowsDeleted    sql.NullInt64
+		RowsFailed     sql.NullInt64
+		Error          sql.NullString
+		StartedAt      sql.NullString
+		FinishedAt     sql.NullString
+		RolledBackAt   sql.NullString
+		CreatedAt      sql.NullString
+		UpdatedAt      sql.NullString
+	)
+
+	err := row.Scan(
+		&Guid,
+		&Entity,
+		&FileName,
+		&FilePath,
+		&Checksum,
+		&UploadedBy,
+		&IdempotencyKey,
+		&Format,
+		&Mode,
+		&Strategy,
+		&Loader,
+		&MappingProfile,
+		&OnMissing,
+		&Sheet,
+		&Status,
+		&RowsProcessed,
+		&RowsInserted,
+		&RowsUpdated,
+		&RowsSkipped,
+		&RowsDeleted,
+		&RowsFailed,
+		&Error,
+		&StartedAt,
+		&FinishedAt,
+		&RolledBackAt,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ImportJob{
+		Guid:             Guid.String,
+		Entity:           Entity.String,
+		FileName:         FileName.String,
+		FilePath:         FilePath.String,
+		Checksum:         Checksum.String,
+		UploadedBy:       UploadedBy.String,
+		IdempotencyKey:   IdempotencyKey.String,
+		Format:           Format.String,
+		Mode:             Mode.String,
+		Strategy:         Strategy.String,
+		Loader:           Loader.String,
+		MappingProfileId: MappingProfile.String,
+		OnMissing:        OnMissing.String,
+		Sheet:            Sheet.String,
+		Status:           Status.String,
+		RowsProcessed:    int(RowsProcessed.Int64),
+		RowsInserted:     int(RowsInserted.Int64),
+		RowsUpdated:      int(RowsUpdated.Int64),
+		RowsSkipped:      int(RowsSkipped.Int64),
+		RowsDeleted:      int(RowsDeleted.Int64),
+		RowsFailed:       int(RowsFailed.Int64),
+		Error:            Error.String,
+		StartedAt:        StartedAt.String,
+		FinishedAt:       FinishedAt.String,
+		RolledBackAt:     RolledBackAt.String,
+		CreatedAt:        CreatedAt.String,
+		UpdatedAt:        UpdatedAt.String,
+	}, nil
+}
+
+func (r *ImportJobRepo) GetById(req models.ImportJobPrimaryKey) (*models.ImportJob, error) {
+	return scanImportJob(r.db.QueryRow(`SELECT `+importJobColumns+` FROM import_jobs WHERE guid = $1`, req.Id))
+}
+
+// GetDuplicate finds the job req would repeat. With an idempotency key only
+// the job created with that key counts; otherwise the newest job that
+// imported the same file into the same entity with the same options and did
+// not fail, get cancelled or rolled back. It returns sql.ErrNoRows if there
+// is none.
+func (r *ImportJobRepo) GetDuplicate(req models.CreateImportJob) (*models.ImportJob, error) {
+	if req.IdempotencyKey != "" {
+		return scanImportJob(r.db.QueryRow(`SELECT `+importJobColumns+` FROM import_jobs WHERE idempotency_key = $1`, req.IdempotencyKey))
+	}
+
+	return scanImportJob(r.db.QueryRow(`
+		SELECT `+importJobColumns+`
+		FROM import_jobs
+		WHERE entity = $1 AND checksum = $2 AND mode = $3 AND strategy = $4 AND loader = $5
+			AND COALESCE(mapping_profile_id::text, '') = $6 AND COALESCE(on_missing, '') = $7
+			AND COALESCE(sheet, '') = $8
+			AND status NOT IN ('failed', 'cancelled', 'rolled_back')
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, req.Entity, req.Checksum, req.Mode, req.Strategy, req.Loader, req.MappingProfileId, req.OnMissing, req.Sheet))
+}
+
+// GetList returns the import history, newest first.
+func (r *ImportJobRepo) GetList(req models.GetListImportJobRequest) (*models.GetListImportJobResponse, error) {
+	var jobs = models.GetListImportJobResponse{}
+	offset := req.Offset
+	limit := req.Limit
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM import_jobs WHERE $1 = '' OR entity = $1`, req.Entity).Scan(&jobs.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+importJobColumns+`
+		FROM import_jobs
+		WHERE $1 = '' OR entity = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`, req.Entity, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanImportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs.ImportJobs = append(jobs.ImportJobs, *job)
+	}
+
+	return &jobs, rows.Err()
+}
+
+// Update moves the job to req.Status. Finished jobs (completed, failed,
+// cancelled, rolled back) are never touched again, so a late progress report
+// cannot resurrect a cancelled job.
+func (r *ImportJobRepo) Update(req models.UpdateImportJob) (*models.ImportJob, error) {
+	_, err := r.db.Exec(`
+		UPDATE import_jobs
+		SET
+			status = $2,
+			rows_processed = $3,
+			rows_inserted = $4,
+			rows_updated = $5,
+			rows_skipped = $6,
+			rows_deleted = $7,
+			rows_failed = $8,
+			error = $9,
+			started_at = CASE WHEN $2 = 'queued' THEN NULL WHEN $2 = 'running' AND started_at IS NULL THEN NOW() ELSE started_at END,
+			finished_at = CASE WHEN $2 IN ('completed', 'failed', 'cancelled') THEN NOW() ELSE finished_at END,
+			updated_at = NOW()
+		WHERE guid = $1 AND status NOT IN ('completed', 'failed', 'cancelled', 'rolled_back')
+	`,
+		req.Guid,
+		req.Status,
+		req.RowsProcessed,
+		req.RowsInserted,
+		req.RowsUpdated,
+		req.RowsSkipped,
+		req.RowsDeleted,
+		req.RowsFailed,
+		helpers.NewNullString(req.Error),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetById(models.ImportJobPrimaryKey{Id: req.Guid})
+}
+
+// Requeue puts the jobs a stopped process left running back in the queue
+// and returns every queued job, oldest first, for the pool to pick up. An
+// import runs in one transaction, so an interrupted one wrote nothing and
+// runs again from the start. Only one process may run the pool.
+func (r *ImportJobRepo) Requeue() ([]string, error) {
+	_, err := r.db.Exec(`
+		UPDATE import_jobs
+		SET
+			status = 'queued',
+			rows_processed = 0,
+			rows_inserted = 0,
+			rows_updated = 0,
+			rows_skipped = 0,
+			rows_deleted = 0,
+			rows_failed = 0,
+			started_at = NULL,
+			updated_at = NOW()
+		WHERE status = 'running'
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT guid FROM import_jobs WHERE status = 'queued' ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Delete removes a job that never started, so that neither it nor its
+// Idempotency-Key is found again. Jobs past the queue are kept.
+func (r *ImportJobRepo) Delete(req models.ImportJobPrimaryKey) error {
+	_, err := r.db.Exec(`DELETE FROM import_jobs WHERE guid = $1 AND status = 'queued'`, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ImportJobRepo) CreateReport(req models.CreateImportReport) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, rowErr := range req.Errors {
+		_, err := tx.Exec(`
+			INSERT INTO import_job_errors (job_id, file, row_index, guid, field, reason) VALUES ($1, $2, $3, $4, $5, $6)`,
+			req.JobId, helpers.NewNullString(rowErr.File), rowErr.Index, rowErr.Guid, rowErr.Field, rowErr.Reason,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *ImportJobRepo) GetReport(req models.ImportJobPrimaryKey) (*models.ImportReport, error) {
+	job, err := r.GetById(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var report = models.ImportReport{Job: *job}
+
+	rows, err := r.db.Query(`
+		SELECT
+			file,
+			row_index,
+			guid,
+			field,
+			reason
+		FROM import_job_errors
+		WHERE job_id = $1
+		ORDER BY file NULLS FIRST, row_index
+	`, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			File   sql.NullString
+			Index  sql.NullInt64
+			Guid   sql.NullString
+			Field  sql.NullString
+			Reason sql.NullString
+		)
+
+		err = rows.Scan(
+			&File,
+			&Index,
+			&Guid,
+			&Field,
+			&Reason,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Errors = append(report.Errors, models.ImportRowError{
+			File:   File.String,
+			Index:  int(Index.Int64),
+			Guid:   Guid.String,
+			Field:  Field.String,
+			Reason: Reason.String,
+		})
+	}
+	// Only the first ImportMaxErrors errors are kept, the count is of all.
+	report.Count = len(report.Errors)
+	if job.RowsFailed > report.Count {
+		report.Count = job.RowsFailed
+	}
+
+	return &report, rows.Err()
+}
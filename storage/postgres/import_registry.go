@@ -0,0 +1,301 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+	"ret/api/models"
+	"ret/pkg/dataset"
+	"sort"
+	"strings"
+)
+
+// importer is an entity the upload endpoints accept: the model the file is
+// bound to, the fields a row cannot do without and where rows are written.
+// FileNames recognise its files in archives without a manifest. Renamed
+// maps the fields of the model whose column is named differently.
+type importer struct {
+	Slug      string
+	Model     interface{}
+	Required  []string
+	FileNames []string
+	Table     importTable
+	Values    importValues
+	Renamed   map[string]string
+
+	// List builds the filters and the sort of an export from the list
+	// request of the entity. Without it every row is exported.
+	List func(req models.ExportRequest) (*listQuery, error)
+
+	// rank is the position in the registry. Archives are imported in this
+	// order, so an entity must be registered after the ones it references.
+	rank int
+}
+
+// importers is the registry behind /upload/:table_slug. A new entity only
+// has to be added here.
+var importers = registerImporters(
+	countryImporter,
+	timezoneImporter,
+	cityImporter,
+	airportImporter,
+)
+
+func registerImporters(list ...importer) map[string]importer {
+	registry := make(map[string]importer, len(list))
+	for i, imp := range list {
+		if _, exists := registry[imp.Slug]; exists {
+			panic("import: duplicate importer " + imp.Slug)
+		}
+		imp.rank = i
+		registry[imp.Slug] = imp
+	}
+
+	return registry
+}
+
+type ImportRepo struct {
+	db *sql.DB
+}
+
+func NewImportRepo(db *sql.DB) *ImportRepo {
+	return &ImportRepo{
+		db: db,
+	}
+}
+
+func (r *ImportRepo) Schemas() []models.ImportSchema {
+	schemas := make([]models.ImportSchema, 0, len(importers))
+	for _, imp := range importers {
+		schemas = append(schemas, imp.schema())
+	}
+
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Slug < schemas[j].Slug
+	})
+
+	return schemas
+}
+
+func (r *ImportRepo) Schema(slug string) (*models.ImportSchema, bool) {
+	imp, ok := importers[slug]
+	if !ok {
+		return nil, false
+	}
+
+	schema := imp.schema()
+	return &schema, true
+}
+
+func (r *ImportRepo) ImportFile(ctx context.Context, slug string, req models.ImportRequest) (*models.ImportResult, error) {
+	imp, ok := importers[slug]
+	if !ok {
+		return nil, fmt.Errorf("unknown import entity: %s", slug)
+	}
+
+	var err error
+	req.Sheet, err = imp.sheetOf(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return importFile(ctx, r.db, imp.Table, req, imp.Values)
+}
+
+// ImportBundle imports the files of an archive in one transaction, in the
+// order of the registry, so countries are in place before the cities that
+// reference them. If a file fails the result so far is returned with the
+// error.
+func (r *ImportRepo) ImportBundle(ctx context.Context, req models.ImportRequest, files []models.ImportBundleFile) (*models.ImportResult, error) {
+	type bundleFile struct {
+		models.ImportBundleFile
+		importer importer
+	}
+
+	bundle := make([]bundleFile, 0, len(files))
+	for _, file := range files {
+		imp, ok := importerOf(file)
+		if !ok && file.Entity == "" {
+			return nil, fmt.Errorf("%s: cannot tell the table from the file name, add a %s", file.Name, dataset.ManifestName)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown table %q", file.Name, file.Entity)
+		}
+		bundle = append(bundle, bundleFile{file, imp})
+	}
+
+	sort.SliceStable(bundle, func(i, j int) bool {
+		return bundle[i].importer.rank < bundle[j].importer.rank
+	})
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	total := models.ImportResult{DryRun: req.DryRun}
+	for _, file := range bundle {
+		var current models.ImportResult
+
+		fileReq := req
+		fileReq.FilePath = file.FilePath
+		fileReq.Format = file.Format
+		fileReq.Sheet = file.Sheet
+		fileReq.Sheet, err = file.importer.sheetOf(fileReq)
+		if err != nil {
+			return &total, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		fileReq.OnProgress = func(result models.ImportResult) {
+			current = result
+			req.Progress(addCounts(total, result))
+		}
+
+		result, err := importTx(ctx, tx, file.importer.Table, fileReq, file.importer.Values)
+		if result != nil {
+			current = *result
+		}
+		addFile(&total, file.Name, file.importer.Slug, current)
+
+		if err != nil {
+			return &total, fmt.Errorf("%s: %w", file.Name, err)
+		}
+	}
+	req.Progress(total)
+
+	if req.DryRun {
+		return &total, tx.Rollback()
+	}
+
+	if err := checkCancelled(ctx, tx, req.BatchId); err != nil {
+		return &total, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &total, nil
+}
+
+// column is the table column of a field of the model.
+func (imp importer) column(field string) string {
+	if column, ok := imp.Renamed[field]; ok {
+		return column
+	}
+
+	return field
+}
+
+// sheetOf picks the sheet of the XLSX file of req to import: the one asked
+// for, or else the one named after the table, or else the first one.
+func (imp importer) sheetOf(req models.ImportRequest) (string, error) {
+	if req.Format != dataset.FormatXLSX || req.Sheet != "" {
+		return req.Sheet, nil
+	}
+
+	sheets, err := dataset.Sheets(req.FilePath)
+	if err != nil {
+		return "", err
+	}
+	for _, sheet := range sheets {
+		if named, ok := importerOf(models.ImportBundleFile{Entity: strings.TrimSpace(sheet)}); ok && named.Slug == imp.Slug {
+			return sheet, nil
+		}
+	}
+
+	return "", nil
+}
+
+// importerOf finds the importer of an archive file by its declared entity,
+// which may also be a table or file name, or else by its file name.
+func importerOf(file models.ImportBundleFile) (importer, bool) {
+	name := strings.ToLower(file.Entity)
+	if name == "" {
+		name = strings.ToLower(path.Base(file.Name))
+		name = strings.TrimSuffix(name, ".gz")
+		name = strings.TrimSuffix(name, path.Ext(name))
+	}
+
+	for _, imp := range importers {
+		if name == imp.Slug || name == imp.Table.Name {
+			return imp, true
+		}
+		for _, fileName := range imp.FileNames {
+			if name == fileName {
+				return imp, true
+			}
+		}
+	}
+
+	return importer{}, false
+}
+
+// addCounts adds the row counts of result to total, leaving the errors out.
+func addCounts(total, result models.ImportResult) models.ImportResult {
+	total.RowsProcessed += result.RowsProcessed
+	total.RowsInserted += result.RowsInserted
+	total.RowsUpdated += result.RowsUpdated
+	total.RowsSkipped += result.RowsSkipped
+	total.RowsDeleted += result.RowsDeleted
+	total.RowsFailed += result.RowsFailed
+	total.StubsCreated += result.StubsCreated
+
+	return total
+}
+
+// addFile adds the result of one archive file to total.
+func addFile(total *models.ImportResult, name, entity string, result models.ImportResult) {
+	*total = addCounts(*total, result)
+
+	for _, rowErr := range result.Errors {
+		if len(total.Errors) == models.ImportMaxErrors {
+			break
+		}
+		rowErr.File = name
+		total.Errors = append(total.Errors, rowErr)
+	}
+
+	total.Files = append(total.Files, models.ImportFileResult{
+		File:          name,
+		Entity:        entity,
+		RowsProcessed: result.RowsProcessed,
+		RowsInserted:  result.RowsInserted,
+		RowsUpdated:   result.RowsUpdated,
+		RowsSkipped:   result.RowsSkipped,
+		RowsDeleted:   result.RowsDeleted,
+		RowsFailed:    result.RowsFailed,
+		StubsCreated:  result.StubsCreated,
+	})
+}
+
+func (imp importer) schema() models.ImportSchema {
+	required := make(map[string]bool, len(imp.Required))
+	for _, name := range imp.Required {
+		required[name] = true
+	}
+
+	fields := dataset.Fields(imp.Model)
+	for _, column := range imp.Table.Columns {
+		if column == dataset.LegacyIdColumn {
+			fields = append(fields, dataset.Field{Name: column, Type: "string"})
+		}
+	}
+
+	schema := models.ImportSchema{
+		Slug:      imp.Slug,
+		Table:     imp.Table.Name,
+		FileNames: imp.FileNames,
+		Fields:    make([]models.ImportField, 0, len(fields)),
+	}
+	for _, field := range fields {
+		schema.Fields = append(schema.Fields, models.ImportField{
+			Name:     field.Name,
+			Type:     field.Type,
+			Required: required[field.Name],
+		})
+	}
+
+	return schema
+}
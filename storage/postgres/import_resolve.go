@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ret/api/models"
+	"ret/pkg/helpers"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+// importKey is a column a referenced row can be found by, compared case
+// insensitively. Only values Match accepts are looked up, if it is set.
+// Scope is a column both tables have that must be equal, like the country
+// of a city; the reference it holds has to be resolved first. Stub copies
+// the value into the column of stub rows, Upper upper-cases it there.
+type importKey struct {
+	Column string
+	Match  func(value string) bool
+	Scope  string
+	Stub   bool
+	Upper  bool
+}
+
+var (
+	countryKeys = []importKey{
+		{Column: "code", Match: isCountryCode, Stub: true, Upper: true},
+		{Column: "title", Stub: true},
+	}
+	cityKeys = []importKey{
+		{Column: "city_code"},
+		{Column: "title", Scope: "country_id", Stub: true},
+	}
+	timezoneKeys = []importKey{
+		{Column: "title", Match: helpers.IsValidTimezone, Stub: true},
+	}
+)
+
+func isCountryCode(value string) bool {
+	return helpers.IsValidCountryCode(strings.ToUpper(value))
+}
+
+// importResolver turns the references of imported rows into guids, see
+// importReference. Lookups are cached for the whole import, so a file of
+// many cities in a few countries only asks for each country once.
+type importResolver struct {
+	tx        *sql.Tx
+	table     importTable
+	onMissing string
+	batchId   string
+	dryRun    bool
+
+	found map[string]string
+	// planned are the stubs a dry run counted instead of creating them.
+	planned map[string]bool
+	// added are the lookups the stubs of the current row answered.
+	added []string
+	stubs int
+}
+
+func newImportResolver(tx *sql.Tx, table importTable, req models.ImportRequest) *importResolver {
+	return &importResolver{
+		tx:        tx,
+		table:     table,
+		onMissing: req.OnMissing,
+		batchId:   req.BatchId,
+		dryRun:    req.DryRun,
+		found:     make(map[string]string),
+		planned:   make(map[string]bool),
+	}
+}
+
+// resolve replaces the references in the values of a row with guids.
+func (r *importResolver) resolve(ctx context.Context, args []interface{}) error {
+	r.added = r.added[:0]
+
+	for _, ref := range r.table.References {
+		i := r.table.column(ref.Column)
+
+		value := strings.TrimSpace(cast.ToString(args[i]))
+		derived := false
+		for _, from := range ref.From {
+			if value != "" {
+				break
+			}
+			value = strings.TrimSpace(cast.ToString(args[r.table.column(from)]))
+			derived = true
+		}
+
+		if value == "" {
+			if ref.Nullify {
+				args[i] = nil
+			}
+			continue
+		}
+
+		guid, err := r.lookup(ctx, ref, value, args)
+		if err != nil {
+			return err
+		}
+		// A name taken from a From column used to be stored as it was,
+		// it only fails the row if the import asks for it.
+		if guid == "" && !(derived && r.onMissing == "") {
+			guid, err = r.miss(ctx, ref, value, args)
+			if err != nil {
+				return err
+			}
+		}
+
+		if guid == "" {
+			args[i] = nil
+		} else {
+			args[i] = guid
+		}
+	}
+
+	return nil
+}
+
+// forget drops the stubs of the current row from the cache, because the
+// row was rolled back to its savepoint and they went with it.
+func (r *importResolver) forget() {
+	for _, key := range r.added {
+		delete(r.found, key)
+		delete(r.planned, key)
+	}
+	r.stubs -= len(r.added)
+	r.added = r.added[:0]
+}
+
+// lookup finds the guid of the row value refers to, by guid or else by the
+// first key that matches. It returns "" if there is none.
+func (r *importResolver) lookup(ctx context.Context, ref importReference, value string, args []interface{}) (string, error) {
+	cacheKey := r.cacheKey(ref, value, args)
+	if guid, ok := r.found[cacheKey]; ok {
+		return guid, nil
+	}
+
+	var guid string
+	if helpers.IsValidUUID(value) {
+		exists, err := rowExists(ctx, r.tx, ref.Table, value)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			guid = value
+		}
+	} else {
+		for _, key := range ref.Keys {
+			if key.Match != nil && !key.Match(value) {
+				continue
+			}
+
+			guids, err := r.find(ctx, ref.Table, key, value, args)
+			if err != nil {
+				return "", err
+			}
+			if len(guids) > 1 {
+				return "", &fieldError{ref.Column, fmt.Sprintf("%q matches several rows of %s", value, ref.Table)}
+			}
+			if len(guids) == 1 {
+				guid = guids[0]
+				break
+			}
+		}
+	}
+
+	r.found[cacheKey] = guid
+	return guid, nil
+}
+
+// find returns up to two guids of the rows of table whose key column is
+// value. It skips the scope if the row has none.
+func (r *importResolver) find(ctx context.Context, table string, key importKey, value string, args []interface{}) ([]string, error) {
+	query := `SELECT guid::text FROM ` + table + ` WHERE lower("` + key.Column + `") = lower($1)`
+	params := []interface{}{value}
+	if scope := r.scope(key, args); scope != "" {
+		query += ` AND "` + key.Scope + `"::text = $2`
+		params = append(params, scope)
+	}
+
+	rows, err := r.tx.QueryContext(ctx, query+` LIMIT 2`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guids []string
+	for rows.Next() {
+		var guid string
+		if err := rows.Scan(&guid); err != nil {
+			return nil, err
+		}
+		guids = append(guids, guid)
+	}
+
+	return guids, rows.Err()
+}
+
+// miss applies the OnMissing policy of the import to a value that matches
+// no row.
+func (r *importResolver) miss(ctx context.Context, ref importReference, value string, args []interface{}) (string, error) {
+	policy := r.onMissing
+	if policy == "" {
+		policy = models.ImportOnMissingReject
+		if ref.Nullify {
+			policy = models.ImportOnMissingNull
+		}
+	}
+
+	switch policy {
+	case models.ImportOnMissingNull:
+		return "", nil
+	case models.ImportOnMissingStub:
+		return r.stub(ctx, ref, value, args)
+	}
+
+	return "", &fieldError{ref.Column, ref.Reason}
+}
+
+// stub creates a row of the referenced table for value. A guid keeps its
+// value, so the real row can replace the stub later on; a natural key is
+// copied into the stub columns of its keys, together with their scope. The
+// stub belongs to the batch, so rolling the import back removes it. A dry
+// run only counts the stub and leaves the reference empty.
+func (r *importResolver) stub(ctx context.Context, ref importReference, value string, args []interface{}) (string, error) {
+	columns := []string{"guid"}
+	values := []interface{}{value}
+
+	if !helpers.IsValidUUID(value) {
+		values[0] = uuid.New().String()
+
+		for _, key := range ref.Keys {
+			if !key.Stub || key.Match != nil && !key.Match(value) {
+				continue
+			}
+
+			stubValue := value
+			if key.Upper {
+				stubValue = strings.ToUpper(value)
+			}
+			columns = append(columns, key.Column)
+			values = append(values, stubValue)
+
+			if scope := r.scope(key, args); scope != "" {
+				columns = append(columns, key.Scope)
+				values = append(values, scope)
+			}
+		}
+	}
+
+	if len(columns) == 1 && values[0] != value {
+		return "", &fieldError{ref.Column, fmt.Sprintf("%s, and no stub can be made of %q", ref.Reason, value)}
+	}
+
+	cacheKey := r.cacheKey(ref, value, args)
+	if r.dryRun {
+		if !r.planned[cacheKey] {
+			r.planned[cacheKey] = true
+			r.added = append(r.added, cacheKey)
+			r.stubs++
+		}
+		return "", nil
+	}
+
+	placeholders := make([]string, 0, len(columns))
+	for i := range columns {
+		columns[i] = `"` + columns[i] + `"`
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+	}
+	batch := fmt.Sprintf("$%d::uuid", len(columns)+1)
+
+	// The copy loader has no savepoint per row, a stub that cannot be
+	// written must not abort the transaction.
+	if _, err := r.tx.ExecContext(ctx, `SAVEPOINT import_stub`); err != nil {
+		return "", err
+	}
+
+	_, err := r.tx.ExecContext(ctx, `
+		WITH stub AS (
+			INSERT INTO `+ref.Table+` (`+strings.Join(columns, ", ")+`, "import_batch_id")
+			VALUES (`+strings.Join(placeholders, ", ")+`, `+batch+`)
+			RETURNING guid
+		)
+		INSERT INTO import_row_versions (batch_id, table_name, guid, action)
+		SELECT `+batch+`, '`+ref.Table+`', guid::text, 'inserted' FROM stub
+		ON CONFLICT DO NOTHING
+	`, append(values, r.batchId)...)
+	if err != nil {
+		if _, rollbackErr := r.tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_stub`); rollbackErr != nil {
+			return "", rollbackErr
+		}
+		return "", &fieldError{ref.Column, "stub does not create: " + err.Error()}
+	}
+
+	if _, err := r.tx.ExecContext(ctx, `RELEASE SAVEPOINT import_stub`); err != nil {
+		return "", err
+	}
+
+	guid := cast.ToString(values[0])
+	r.found[cacheKey] = guid
+	r.added = append(r.added, cacheKey)
+	r.stubs++
+
+	return guid, nil
+}
+
+// scope is the value the row has for the scope column of key, if any.
+func (r *importResolver) scope(key importKey, args []interface{}) string {
+	if key.Scope == "" {
+		return ""
+	}
+
+	return cast.ToString(args[r.table.column(key.Scope)])
+}
+
+func (r *importResolver) cacheKey(ref importReference, value string, args []interface{}) string {
+	key := ref.Column + "\x00" + strings.ToLower(value)
+	for _, k := range ref.Keys {
+		if k.Scope != "" {
+			key += "\x00" + r.scope(k, args)
+		}
+	}
+
+	return key
+}
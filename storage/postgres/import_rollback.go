@@ -0,0 +1,195 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ret/api/models"
+	"ret/storage"
+	"sort"
+	"strings"
+)
+
+// Rollback undoes a completed import batch from its before-images: inserted
+// rows are deleted, updated rows get their previous values back and rows a
+// replace-all import deleted are inserted again. An archive batch is undone
+// across all of its tables, along with the rows those deletes cascaded to.
+//
+// Nothing is touched if a row of the batch has since been written by another
+// import or changed by hand, because restoring it would lose that change. A
+// change by hand is told by an updated_at past the end of the job.
+func (r *ImportJobRepo) Rollback(ctx context.Context, req models.ImportJobPrimaryKey) (*models.ImportRollback, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM import_jobs WHERE guid = $1 FOR UPDATE`, req.Id).Scan(&status)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != models.ImportJobCompleted {
+		return nil, fmt.Errorf("%w: job is %s", storage.ErrRollbackConflict, status)
+	}
+
+	tables, err := batchTables(ctx, tx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range tables {
+		var conflicts int
+		// A deleted row must still be gone, an updated one still there, and
+		// the rows still there as the batch left them.
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*)
+			FROM import_row_versions v
+			JOIN import_jobs j ON j.guid = v.batch_id
+			LEFT JOIN `+table+` t ON t.guid::text = v.guid
+			WHERE v.batch_id = $1 AND v.table_name = $2
+				AND CASE
+					WHEN v.action = 'deleted' THEN t.guid IS NOT NULL
+					WHEN t.guid IS NULL THEN v.action = 'updated'
+					ELSE t.import_batch_id IS DISTINCT FROM v.batch_id OR t.updated_at > j.finished_at
+				END
+		`, req.Id, table).Scan(&conflicts)
+		if err != nil {
+			return nil, err
+		}
+
+		if conflicts > 0 {
+			return nil, fmt.Errorf("%w: %d rows of %s were changed since the import", storage.ErrRollbackConflict, conflicts, table)
+		}
+	}
+
+	var result models.ImportRollback
+
+	// Children go first, so deleting a parent never cascades into rows
+	// that are about to be restored.
+	for i := len(tables) - 1; i >= 0; i-- {
+		res, err := tx.ExecContext(ctx, `
+			DELETE FROM `+tables[i]+` t
+			USING import_row_versions v
+			WHERE v.batch_id = $1 AND v.table_name = $2 AND v.action = 'inserted' AND t.guid::text = v.guid
+		`, req.Id, tables[i])
+		if err != nil {
+			return nil, err
+		}
+		deleted, _ := res.RowsAffected()
+		result.RowsDeleted += int(deleted)
+	}
+
+	for _, table := range tables {
+		columns, err := tableColumns(ctx, tx, table)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE `+table+` t
+			SET (`+strings.Join(columns, ", ")+`) = (
+				SELECT `+strings.Join(columns, ", ")+` FROM jsonb_populate_record(NULL::`+table+`, v.before)
+			)
+			FROM import_row_versions v
+			WHERE v.batch_id = $1 AND v.table_name = $2 AND v.action = 'updated' AND t.guid::text = v.guid
+		`, req.Id, table)
+		if err != nil {
+			return nil, err
+		}
+		updated, _ := res.RowsAffected()
+
+		res, err = tx.ExecContext(ctx, `
+			INSERT INTO `+table+`
+			SELECT r.*
+			FROM import_row_versions v, jsonb_populate_record(NULL::`+table+`, v.before) r
+			WHERE v.batch_id = $1 AND v.table_name = $2 AND v.action = 'deleted'
+		`, req.Id, table)
+		if err != nil {
+			return nil, err
+		}
+		restored, _ := res.RowsAffected()
+		result.RowsRestored += int(updated + restored)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE import_jobs
+		SET status = $2, rolled_back_at = NOW(), updated_at = NOW()
+		WHERE guid = $1
+	`, req.Id, models.ImportJobRolledBack)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	job, err := r.GetById(req)
+	if err != nil {
+		return nil, err
+	}
+	result.Job = *job
+
+	return &result, nil
+}
+
+// batchTables returns the tables a batch wrote to, parents before children.
+func batchTables(ctx context.Context, tx *sql.Tx, batchId string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT table_name FROM import_row_versions WHERE batch_id = $1`, batchId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rank := make(map[string]int, len(importers))
+	for _, imp := range importers {
+		rank[imp.Table.Name] = imp.rank
+	}
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+
+		if _, ok := rank[table]; !ok {
+			return nil, fmt.Errorf("unknown import table: %s", table)
+		}
+		tables = append(tables, table)
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return rank[tables[i]] < rank[tables[j]]
+	})
+
+	return tables, rows.Err()
+}
+
+// tableColumns lists the quoted columns of table except guid.
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND column_name <> 'guid'
+		ORDER BY ordinal_position
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, `"`+column+`"`)
+	}
+
+	return columns, rows.Err()
+}
@@ -0,0 +1,381 @@
+package postgres
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"ret/pkg/helpers"
+	"ret/storage"
+	"strings"
+)
+
+// maxListLimit is the most rows a page of a list or of search results has.
+const maxListLimit = 100
+
+// listQuery is the WHERE, ORDER BY and LIMIT clause of a list query. Values
+// are bound as parameters and columns only come from the repos, never from
+// the request.
+type listQuery struct {
+	where []string
+	args  []interface{}
+	keys  []sortKey
+
+	// filters is how many of args belong to where.
+	filters int
+	limit   int
+	offset  int
+	// seek is the condition of a keyset page, the rows past cursor.
+	seek   string
+	cursor *listCursor
+}
+
+// sortKey is a column of the order of a list.
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// listCursor is what a cursor token holds: the sort it belongs to, the sort
+// keys of the row it points at and whether the page is the one before it.
+type listCursor struct {
+	Sort string          `json:"s"`
+	Key  json.RawMessage `json:"k"`
+	Back bool            `json:"b,omitempty"`
+}
+
+// arg binds value and returns its placeholder.
+func (q *listQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// add adds a condition, %s in it stands for the placeholder of value.
+func (q *listQuery) add(condition string, value interface{}) {
+	q.where = append(q.where, fmt.Sprintf(condition, q.arg(value)))
+}
+
+// equal filters by a text column, case insensitively, if value is set.
+func (q *listQuery) equal(column, value string) {
+	if value != "" {
+		q.add(`lower("`+column+`") = lower(%s)`, value)
+	}
+}
+
+// prefix filters by the beginning of a text column, case insensitively.
+func (q *listQuery) prefix(column, value string) {
+	if value != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+		q.add(`"`+column+`" ILIKE %s`, escaped+"%")
+	}
+}
+
+// guid filters by a uuid column, if value is set.
+func (q *listQuery) guid(column, value string) error {
+	if value == "" {
+		return nil
+	}
+	if !helpers.IsValidUUID(value) {
+		return fmt.Errorf("%w: %s is not uuid", storage.ErrInvalidListQuery, column)
+	}
+
+	q.add(`"`+column+`" = %s`, value)
+	return nil
+}
+
+// between filters by an integer column, the bounds are inclusive.
+func (q *listQuery) between(column string, min, max *int) error {
+	if min != nil && max != nil && *min > *max {
+		return fmt.Errorf("%w: %s range is empty", storage.ErrInvalidListQuery, column)
+	}
+
+	if min != nil {
+		q.add(`"`+column+`" >= %s`, *min)
+	}
+	if max != nil {
+		q.add(`"`+column+`" <= %s`, *max)
+	}
+	return nil
+}
+
+// sort orders by a comma separated list of the fields, a leading "-"
+// sorts descending. Rows are ordered by created_at by default and by guid
+// last, so the order is stable.
+func (q *listQuery) sort(sort string, fields []string) error {
+	for _, term := range strings.Split(sort, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		desc := false
+		switch term[0] {
+		case '-':
+			desc = true
+			term = term[1:]
+		case '+':
+			term = term[1:]
+		}
+
+		if !contains(fields, term) {
+			return fmt.Errorf("%w: cannot sort by %q, sort by %s", storage.ErrInvalidListQuery, term, strings.Join(fields, ", "))
+		}
+		q.keys = append(q.keys, sortKey{column: term, desc: desc})
+	}
+
+	if len(q.keys) == 0 {
+		q.keys = append(q.keys, sortKey{column: "created_at"})
+	}
+	q.keys = append(q.keys, sortKey{column: "guid"})
+	return nil
+}
+
+// paginate selects the page of limit rows at offset, or the page next to
+// the row of a cursor, in which case offset does not count. A limit over
+// maxListLimit is cut down to it.
+func (q *listQuery) paginate(offset, limit int, cursor string) error {
+	q.filters = len(q.args)
+	q.limit = limit
+	if q.limit > maxListLimit {
+		q.limit = maxListLimit
+	}
+	if cursor == "" {
+		q.offset = offset
+		return nil
+	}
+
+	decoded, values, err := q.decodeCursor(cursor)
+	if err != nil {
+		return fmt.Errorf("%w: cursor is not valid for this list", storage.ErrInvalidListQuery)
+	}
+	q.cursor = decoded
+
+	// Past the row in the order of the keys is any row that equals it on
+	// the keys before one and comes after it on that one.
+	var seek []string
+	for i, key := range q.keys {
+		after := q.after(key, values[i], decoded.Back)
+		if after == "" {
+			continue
+		}
+
+		conditions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, q.equals(q.keys[j], values[j]))
+		}
+		conditions = append(conditions, after)
+		seek = append(seek, "("+strings.Join(conditions, " AND ")+")")
+	}
+	if len(seek) == 0 {
+		seek = append(seek, "FALSE")
+	}
+	q.seek = "(" + strings.Join(seek, " OR ") + ")"
+
+	return nil
+}
+
+// after is the condition of the rows that come after value in the column
+// of key, or before it if back is set. Postgres sorts NULL last ascending
+// and first descending, which is the order followed here.
+func (q *listQuery) after(key sortKey, value interface{}, back bool) string {
+	column := `"` + key.column + `"`
+	if key.desc != back {
+		if value == nil {
+			return column + " IS NOT NULL"
+		}
+		return column + " < " + q.arg(value)
+	}
+
+	if value == nil {
+		return ""
+	}
+	return "(" + column + " > " + q.arg(value) + " OR " + column + " IS NULL)"
+}
+
+func (q *listQuery) equals(key sortKey, value interface{}) string {
+	if value == nil {
+		return `"` + key.column + `" IS NULL`
+	}
+	return `"` + key.column + `" = ` + q.arg(value)
+}
+
+// key is the column to select next to the fields of a row, the sort keys
+// cursors are made of.
+func (q *listQuery) key() string {
+	columns := make([]string, 0, len(q.keys))
+	for _, key := range q.keys {
+		columns = append(columns, `"`+key.column+`"`)
+	}
+
+	return "json_build_array(" + strings.Join(columns, ", ") + ")::text"
+}
+
+// sql returns the WHERE and ORDER BY clauses.
+func (q *listQuery) sql() string {
+	var b strings.Builder
+
+	where := q.where
+	if q.seek != "" {
+		where = append(where[:len(where):len(where)], q.seek)
+	}
+	if len(where) > 0 {
+		b.WriteString(" WHERE " + strings.Join(where, " AND "))
+	}
+
+	// The page before a cursor is read backwards from it.
+	back := q.cursor != nil && q.cursor.Back
+	order := make([]string, 0, len(q.keys))
+	for _, key := range q.keys {
+		direction := "ASC"
+		if key.desc != back {
+			direction = "DESC"
+		}
+		order = append(order, `"`+key.column+`" `+direction)
+	}
+	if len(order) > 0 {
+		b.WriteString(" ORDER BY " + strings.Join(order, ", "))
+	}
+
+	return b.String()
+}
+
+// page returns the LIMIT clause. One row more than the page is read, to
+// tell whether there is a next one.
+func (q *listQuery) page() string {
+	page := " LIMIT " + q.arg(q.limit+1)
+	if q.cursor == nil {
+		page += " OFFSET " + q.arg(q.offset)
+	}
+
+	return page
+}
+
+// count returns how many rows of table pass the filters.
+func (q *listQuery) count(db *sql.DB, table string) (int, error) {
+	var where string
+	if len(q.where) > 0 {
+		where = " WHERE " + strings.Join(q.where, " AND ")
+	}
+
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM `+table+where, q.args[:q.filters]...).Scan(&count)
+	return count, err
+}
+
+// cursors trims the rows read to the page and returns the cursors of the
+// pages around it. keys are the key column of the rows, swap swaps two of
+// them, as the rows before a cursor are read in reverse and have to be
+// turned around. The rows past n are to be dropped.
+func (q *listQuery) cursors(keys []string, swap func(i, j int)) (n int, next, prev string) {
+	n = len(keys)
+	more := n > q.limit
+	if more {
+		n = q.limit
+	}
+
+	back := q.cursor != nil && q.cursor.Back
+	if back {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	if n == 0 {
+		// Past the end there is still the way back.
+		if q.cursor != nil {
+			if back {
+				next = q.encodeCursor(string(q.cursor.Key), false)
+			} else {
+				prev = q.encodeCursor(string(q.cursor.Key), true)
+			}
+		}
+		return n, next, prev
+	}
+
+	hasNext, hasPrev := more, q.offset > 0 || q.cursor != nil
+	if back {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		next = q.encodeCursor(keys[n-1], false)
+	}
+	if hasPrev {
+		prev = q.encodeCursor(keys[0], true)
+	}
+
+	return n, next, prev
+}
+
+// sorting names the order of the list, so a cursor is not used with
+// another one.
+func (q *listQuery) sorting() string {
+	terms := make([]string, 0, len(q.keys))
+	for _, key := range q.keys {
+		if key.desc {
+			terms = append(terms, "-"+key.column)
+		} else {
+			terms = append(terms, key.column)
+		}
+	}
+
+	return strings.Join(terms, ",")
+}
+
+func (q *listQuery) encodeCursor(key string, back bool) string {
+	data, err := json.Marshal(listCursor{Sort: q.sorting(), Key: json.RawMessage(key), Back: back})
+	if err != nil {
+		// The key is built by Postgres, so it is always valid JSON.
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (q *listQuery) decodeCursor(cursor string) (*listCursor, []interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var decoded listCursor
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, nil, err
+	}
+	if decoded.Sort != q.sorting() {
+		return nil, nil, fmt.Errorf("cursor of another sort: %s", decoded.Sort)
+	}
+
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(decoded.Key))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, nil, err
+	}
+	if len(values) != len(q.keys) {
+		return nil, nil, fmt.Errorf("cursor has %d keys, not %d", len(values), len(q.keys))
+	}
+
+	for i, value := range values {
+		switch value := value.(type) {
+		case nil, string:
+		case json.Number:
+			values[i] = value.String()
+		default:
+			return nil, nil, fmt.Errorf("cursor key %d is not a value", i)
+		}
+	}
+
+	return &decoded, values, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"ret/storage"
+	"strconv"
+	"testing"
+)
+
+// countryQuery is the list query of countries in Asia sorted by sort.
+func countryQuery(t *testing.T, sort string) *listQuery {
+	t.Helper()
+
+	query := &listQuery{}
+	query.equal("continent", "Asia")
+	if err := query.sort(sort, countrySortFields); err != nil {
+		t.Fatal(err)
+	}
+
+	return query
+}
+
+func TestListQuerySQL(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"", ` WHERE lower("continent") = lower($1) ORDER BY "created_at" ASC, "guid" ASC`},
+		{"-title", ` WHERE lower("continent") = lower($1) ORDER BY "title" DESC, "guid" ASC`},
+		{"continent, -title", ` WHERE lower("continent") = lower($1) ORDER BY "continent" ASC, "title" DESC, "guid" ASC`},
+	}
+
+	for _, test := range tests {
+		query := countryQuery(t, test.sort)
+		if err := query.paginate(20, 10, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := query.sql(); got != test.want {
+			t.Errorf("sort %q:\n%s\nwant\n%s", test.sort, got, test.want)
+		}
+		if got, want := query.page(), " LIMIT $2 OFFSET $3"; got != want {
+			t.Errorf("sort %q: page is %q, want %q", test.sort, got, want)
+		}
+		if want := []interface{}{"Asia", 11, 20}; !reflect.DeepEqual(query.args, want) {
+			t.Errorf("sort %q: args are %v, want %v", test.sort, query.args, want)
+		}
+	}
+}
+
+func TestListQueryBadSort(t *testing.T) {
+	query := &listQuery{}
+	err := query.sort("population", countrySortFields)
+	if !errors.Is(err, storage.ErrInvalidListQuery) {
+		t.Errorf("error is %v, want ErrInvalidListQuery", err)
+	}
+}
+
+func TestListQueryCursor(t *testing.T) {
+	tests := []struct {
+		name  string
+		sort  string
+		key   string
+		back  bool
+		want  string
+		args  []interface{}
+		order string
+	}{
+		{
+			name:  "next, ascending",
+			key:   `["2024-01-01T00:00:00","g2"]`,
+			want:  `((("created_at" > $2 OR "created_at" IS NULL)) OR ("created_at" = $4 AND ("guid" > $3 OR "guid" IS NULL)))`,
+			args:  []interface{}{"Asia", "2024-01-01T00:00:00", "g2", "2024-01-01T00:00:00"},
+			order: `ORDER BY "created_at" ASC, "guid" ASC`,
+		},
+		{
+			name:  "next, descending",
+			sort:  "-title",
+			key:   `["Tajikistan","g2"]`,
+			want:  `(("title" < $2) OR ("title" = $4 AND ("guid" > $3 OR "guid" IS NULL)))`,
+			args:  []interface{}{"Asia", "Tajikistan", "g2", "Tajikistan"},
+			order: `ORDER BY "title" DESC, "guid" ASC`,
+		},
+		{
+			// The page before is read the other way round.
+			name:  "prev, descending",
+			sort:  "-title",
+			key:   `["Tajikistan","g2"]`,
+			back:  true,
+			want:  `((("title" > $2 OR "title" IS NULL)) OR ("title" = $4 AND "guid" < $3))`,
+			args:  []interface{}{"Asia", "Tajikistan", "g2", "Tajikistan"},
+			order: `ORDER BY "title" ASC, "guid" DESC`,
+		},
+		{
+			// NULL comes first descending, every title is past it.
+			name:  "next, mixed with a NULL key",
+			sort:  "continent,-title",
+			key:   `["Asia",null,"g2"]`,
+			want:  `((("continent" > $2 OR "continent" IS NULL)) OR ("continent" = $3 AND "title" IS NOT NULL) OR ("continent" = $5 AND "title" IS NULL AND ("guid" > $4 OR "guid" IS NULL)))`,
+			args:  []interface{}{"Asia", "Asia", "Asia", "g2", "Asia"},
+			order: `ORDER BY "continent" ASC, "title" DESC, "guid" ASC`,
+		},
+		{
+			// Nothing comes before NULL read backwards ascending.
+			name:  "prev, mixed with a NULL key",
+			sort:  "continent,-title",
+			key:   `["Asia",null,"g2"]`,
+			back:  true,
+			want:  `(("continent" < $2) OR ("continent" = $4 AND "title" IS NULL AND "guid" < $3))`,
+			args:  []interface{}{"Asia", "Asia", "g2", "Asia"},
+			order: `ORDER BY "continent" DESC, "title" ASC, "guid" DESC`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cursor := countryQuery(t, test.sort).encodeCursor(test.key, test.back)
+
+			query := countryQuery(t, test.sort)
+			if err := query.paginate(20, 10, cursor); err != nil {
+				t.Fatal(err)
+			}
+
+			if query.seek != test.want {
+				t.Errorf("seek is\n%s\nwant\n%s", query.seek, test.want)
+			}
+			if !reflect.DeepEqual(query.args, test.args) {
+				t.Errorf("args are %v, want %v", query.args, test.args)
+			}
+
+			want := ` WHERE lower("continent") = lower($1) AND ` + test.want + ` ` + test.order
+			if got := query.sql(); got != want {
+				t.Errorf("sql is\n%s\nwant\n%s", got, want)
+			}
+
+			// A cursor replaces the offset.
+			if got := query.page(); got != " LIMIT $"+strconv.Itoa(len(test.args)+1) {
+				t.Errorf("page is %q", got)
+			}
+		})
+	}
+}
+
+func TestListQueryCursorRoundTrip(t *testing.T) {
+	query := countryQuery(t, "-title")
+	key := `["Tajikistan","d8f0e6a4-1c1e-4f0e-9f7e-2b8f6c3f2a10"]`
+
+	for _, back := range []bool{false, true} {
+		decoded, values, err := query.decodeCursor(query.encodeCursor(key, back))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(decoded.Key) != key || decoded.Back != back || decoded.Sort != "-title,guid" {
+			t.Errorf("cursor is %+v, want key %s, back %v", decoded, key, back)
+		}
+		if want := []interface{}{"Tajikistan", "d8f0e6a4-1c1e-4f0e-9f7e-2b8f6c3f2a10"}; !reflect.DeepEqual(values, want) {
+			t.Errorf("values are %v, want %v", values, want)
+		}
+	}
+
+	// Numbers are compared as text, Postgres casts them back.
+	airports := &listQuery{}
+	if err := airports.sort("product_count", airportSortFields); err != nil {
+		t.Fatal(err)
+	}
+	_, values, err := airports.decodeCursor(airports.encodeCursor(`[12,"g1"]`, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"12", "g1"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values are %v, want %v", values, want)
+	}
+}
+
+func TestListQueryBadCursor(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not JSON", encode("title")},
+		{"another sort", countryQuery(t, "title").encodeCursor(`["Tajikistan","g2"]`, false)},
+		{"too few keys", encode(`{"s":"-title,guid","k":["Tajikistan"]}`)},
+		{"too many keys", encode(`{"s":"-title,guid","k":["Tajikistan","g2","g3"]}`)},
+		{"key is not a value", encode(`{"s":"-title,guid","k":[{"title":"Tajikistan"},"g2"]}`)},
+		{"keys are not a list", encode(`{"s":"-title,guid","k":"Tajikistan"}`)},
+	}
+
+	for _, test := range tests {
+		query := countryQuery(t, "-title")
+		err := query.paginate(0, 10, test.cursor)
+		if !errors.Is(err, storage.ErrInvalidListQuery) {
+			t.Errorf("%s: error is %v, want ErrInvalidListQuery", test.name, err)
+		}
+	}
+}
+
+func TestListQueryCursors(t *testing.T) {
+	rows := func() ([]string, []string) {
+		keys := []string{`["A","g1"]`, `["B","g2"]`, `["C","g3"]`}
+		return keys, append([]string(nil), keys...)
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		query := countryQuery(t, "title")
+		query.paginate(0, 2, "")
+		keys, rows := rows()
+
+		n, next, prev := query.cursors(keys, func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+		if n != 2 || prev != "" {
+			t.Errorf("n = %d, prev = %q, want 2 rows and no prev", n, prev)
+		}
+		if want := query.encodeCursor(`["B","g2"]`, false); next != want {
+			t.Errorf("next points at %s", cursorKey(t, query, next))
+		}
+	})
+
+	t.Run("last page by offset", func(t *testing.T) {
+		query := countryQuery(t, "title")
+		query.paginate(2, 3, "")
+		keys, _ := rows()
+
+		n, next, prev := query.cursors(keys, func(i, j int) {})
+		if n != 3 || next != "" {
+			t.Errorf("n = %d, next = %q, want 3 rows and no next", n, next)
+		}
+		if want := query.encodeCursor(`["A","g1"]`, true); prev != want {
+			t.Errorf("prev points at %s", cursorKey(t, query, prev))
+		}
+	})
+
+	t.Run("page before a cursor", func(t *testing.T) {
+		query := countryQuery(t, "title")
+		query.paginate(0, 2, query.encodeCursor(`["D","g4"]`, true))
+		// Read backwards: C, B and A, which tells there is a page before.
+		keys := []string{`["C","g3"]`, `["B","g2"]`, `["A","g1"]`}
+		rows := append([]string(nil), keys...)
+
+		n, next, prev := query.cursors(keys, func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+		if n != 2 {
+			t.Fatalf("n = %d, want 2", n)
+		}
+		if want := []string{`["B","g2"]`, `["C","g3"]`}; !reflect.DeepEqual(rows[:n], want) {
+			t.Errorf("rows are %v, want %v", rows[:n], want)
+		}
+		if want := query.encodeCursor(`["C","g3"]`, false); next != want {
+			t.Errorf("next points at %s", cursorKey(t, query, next))
+		}
+		if want := query.encodeCursor(`["B","g2"]`, true); prev != want {
+			t.Errorf("prev points at %s", cursorKey(t, query, prev))
+		}
+	})
+
+	t.Run("past the end", func(t *testing.T) {
+		query := countryQuery(t, "title")
+		query.paginate(0, 2, query.encodeCursor(`["Z","g9"]`, false))
+
+		n, next, prev := query.cursors(nil, func(i, j int) {})
+		if n != 0 || next != "" {
+			t.Errorf("n = %d, next = %q, want nothing", n, next)
+		}
+		if want := query.encodeCursor(`["Z","g9"]`, true); prev != want {
+			t.Errorf("prev points at %s", cursorKey(t, query, prev))
+		}
+	})
+}
+
+// cursorKey describes a cursor for a failure message.
+func cursorKey(t *testing.T, query *listQuery, cursor string) string {
+	t.Helper()
+
+	if cursor == "" {
+		return "nothing"
+	}
+	decoded, _, err := query.decodeCursor(cursor)
+	if err != nil {
+		return err.Error()
+	}
+	return string(decoded.Key)
+}
+
+func TestListQueryMaxLimit(t *testing.T) {
+	query := countryQuery(t, "")
+	if err := query.paginate(0, 100000, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	query.page()
+	if want := []interface{}{"Asia", maxListLimit + 1, 0}; !reflect.DeepEqual(query.args, want) {
+		t.Errorf("args are %v, want %v", query.args, want)
+	}
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"ret/api/models"
+	"ret/pkg/helpers"
+
+	"github.com/google/uuid"
+)
+
+type MappingProfileRepo struct {
+	db *sql.DB
+}
+
+func NewMappingProfileRepo(db *sql.DB) *MappingProfileRepo {
+	return &MappingProfileRepo{
+		db: db,
+	}
+}
+
+func (r *MappingProfileRepo) Create(req models.CreateMappingProfile) (*models.MappingProfile, error) {
+	rules, err := json.Marshal(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var id string
+
+	err = r.db.QueryRow(`
+		INSERT INTO mapping_profiles(
+			guid,
+			name,
+			entity,
+			rules,
+			updated_at
+		) VALUES ($1, $2, $3, $4, NOW()) RETURNING guid`,
+		uuid.New().String(),
+		req.Name,
+		helpers.NewNullString(req.Entity),
+		string(rules),
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetById(models.MappingProfilePrimaryKey{Id: id})
+}
+
+const mappingProfileColumns = `guid, name, entity, rules, created_at, updated_at`
+
+// scanMappingProfile reads a row selected with mappingProfileColumns.
+func scanMappingProfile(row interface{ Scan(...interface{}) error }) (*models.MappingProfile, error) {
+	var (
+		Guid      sql.NullString
+		Name      sql.NullString
+		Entity    sql.NullString
+		Rules     []byte
+		CreatedAt sql.NullString
+		UpdatedAt sql.NullString
+	)
+
+	err := row.Scan(
+		&Guid,
+		&Name,
+		&Entity,
+		&Rules,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := models.MappingProfile{
+		Guid:      Guid.String,
+		Name:      Name.String,
+		Entity:    Entity.String,
+		CreatedAt: CreatedAt.String,
+		UpdatedAt: UpdatedAt.String,
+	}
+	if err := json.Unmarshal(Rules, &profile.Rules); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+func (r *MappingProfileRepo) GetById(req models.MappingProfilePrimaryKey) (*models.MappingProfile, error) {
+	return scanMappingProfile(r.db.QueryRow(`SELECT `+mappingProfileColumns+` FROM mapping_profiles WHERE guid = $1`, req.Id))
+}
+
+// GetList returns the profiles by name. Filtered by entity it also returns
+// the profiles that fit any entity.
+func (r *MappingProfileRepo) GetList(req models.GetListMappingProfileRequest) (*models.GetListMappingProfileResponse, error) {
+	var profiles = models.GetListMappingProfileResponse{}
+	offset := req.Offset
+	limit := req.Limit
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+
+	const where = `WHERE $1 = '' OR entity = $1 OR entity IS NULL`
+
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM mapping_profiles `+where, req.Entity).Scan(&profiles.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+mappingProfileColumns+`
+		FROM mapping_profiles
+		`+where+`
+		ORDER BY name
+		LIMIT $2 OFFSET $3
+	`, req.Entity, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		profile, err := scanMappingProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles.MappingProfiles = append(profiles.MappingProfiles, *profile)
+	}
+
+	return &profiles, rows.Err()
+}
+
+func (r *MappingProfileRepo) Update(req models.UpdateMappingProfile) (*models.MappingProfile, error) {
+	rules, err := json.Marshal(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(`
+		UPDATE mapping_profiles
+		SET
+			name = $2,
+			entity = $3,
+			rules = $4,
+			updated_at = NOW()
+		WHERE guid = $1
+	`, req.Guid, req.Name, helpers.NewNullString(req.Entity), string(rules))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetById(models.MappingProfilePrimaryKey{Id: req.Guid})
+}
+
+func (r *MappingProfileRepo) Delete(req models.MappingProfilePrimaryKey) error {
+	_, err := r.db.Exec(`DELETE FROM mapping_profiles WHERE guid = $1`, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
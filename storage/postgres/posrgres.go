@@ -14,6 +14,12 @@ type Store struct {
 	city    *CityRepo
 	country *CountryRepo
 	airport *AirportRepo
+
+	importJob *ImportJobRepo
+	imports   *ImportRepo
+	upload    *UploadRepo
+	mapping   *MappingProfileRepo
+	search    *SearchRepo
 }
 
 func NewConnectionPostgres(cfg *config.Config) (storage.StorageI, error) {
@@ -56,3 +62,38 @@ func (s *Store) Country() storage.CountryRepoI {
 	}
 	return s.country
 }
+
+func (s *Store) ImportJob() storage.ImportJobRepoI {
+	if s.importJob == nil {
+		s.importJob = NewImportJobRepo(s.db)
+	}
+	return s.importJob
+}
+
+func (s *Store) Import() storage.ImportRepoI {
+	if s.imports == nil {
+		s.imports = NewImportRepo(s.db)
+	}
+	return s.imports
+}
+
+func (s *Store) Upload() storage.UploadRepoI {
+	if s.upload == nil {
+		s.upload = NewUploadRepo(s.db)
+	}
+	return s.upload
+}
+
+func (s *Store) MappingProfile() storage.MappingProfileRepoI {
+	if s.mapping == nil {
+		s.mapping = NewMappingProfileRepo(s.db)
+	}
+	return s.mapping
+}
+
+func (s *Store) Search() storage.SearchRepoI {
+	if s.search == nil {
+		s.search = NewSearchRepo(s.db)
+	}
+	return s.search
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"database/sql"
+	"html"
+	"ret/api/models"
+	"strings"
+	"unicode"
+)
+
+// searchTables are the tables searched, with the columns a hit is made of.
+// Document is the text the search_vector of the table is built from, the
+// highlight is cut out of it.
+var searchTables = []struct {
+	Type      string
+	Table     string
+	Code      string
+	CountryId string
+	Document  string
+}{
+	{models.SearchTypeCountry, "countries", "code", "guid", "concat_ws(' ', title, code, continent)"},
+	{models.SearchTypeCity, "cities", "city_code", "country_id", "concat_ws(' ', title, city_code, country_name)"},
+	{models.SearchTypeAirport, "buildings", "code", "country_id", "concat_ws(' ', title, code, city, country, address, search_text)"},
+}
+
+// searchStartSel and searchStopSel mark the matched words in a headline.
+// They are control characters that no document has, so the headline can be
+// escaped for HTML before they are turned into tags.
+const (
+	searchStartSel = "\x02"
+	searchStopSel  = "\x03"
+)
+
+type SearchRepo struct {
+	db *sql.DB
+}
+
+func NewSearchRepo(db *sql.DB) *SearchRepo {
+	return &SearchRepo{
+		db: db,
+	}
+}
+
+// Search looks for the words of the query in countries, cities and
+// airports, the last word of it may be the beginning of one. Hits are
+// ranked by how often and in which fields the words occur, the title
+// counting most.
+func (r *SearchRepo) Search(req models.SearchRequest) (*models.SearchResponse, error) {
+	var resp = models.SearchResponse{}
+	offset := req.Offset
+	limit := req.Limit
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	terms := searchTerms(req.Query)
+	if terms == "" {
+		return &resp, nil
+	}
+
+	var (
+		query  listQuery
+		search = query.arg(terms)
+		parts  []string
+	)
+
+	var country string
+	if req.CountryId != "" {
+		country = query.arg(req.CountryId)
+	}
+
+	for _, table := range searchTables {
+		if req.Type != "" && req.Type != table.Type {
+			continue
+		}
+
+		part := `
+			SELECT '` + table.Type + `' AS type, t.guid::text AS guid, t.title, t.` + table.Code + ` AS code, t.` + table.CountryId + `::text AS country_id,
+				ts_rank(t.search_vector, s.query) AS rank, ` + table.Document + ` AS document
+			FROM ` + table.Table + ` t, search s
+			WHERE t.search_vector @@ s.query`
+		if country != "" {
+			part += ` AND t.` + table.CountryId + `::text = ` + country
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return &resp, nil
+	}
+
+	matches := strings.Join(parts, " UNION ALL ")
+	filters := len(query.args)
+
+	// The count is taken apart from the page, which is empty past the last
+	// hit.
+	err := r.db.QueryRow(`
+		WITH search AS (SELECT to_tsquery('simple', `+search+`) AS query)
+		SELECT COUNT(*) FROM (`+matches+`) matches
+	`, query.args[:filters]...).Scan(&resp.Count)
+	if err != nil {
+		return nil, err
+	}
+	if offset >= resp.Count {
+		return &resp, nil
+	}
+
+	options := query.arg("StartSel=" + searchStartSel + ", StopSel=" + searchStopSel + ", HighlightAll=true")
+	marks := query.arg(searchStartSel + searchStopSel)
+	page := `LIMIT ` + query.arg(limit) + ` OFFSET ` + query.arg(offset)
+
+	// The highlights are made for the page only, ts_headline is slow.
+	rows, err := r.db.Query(`
+		WITH search AS (SELECT to_tsquery('simple', `+search+`) AS query)
+		SELECT
+			hits.type,
+			hits.guid,
+			hits.title,
+			hits.code,
+			hits.country_id,
+			hits.rank,
+			ts_headline('simple', translate(hits.document, `+marks+`, ''), s.query, `+options+`)
+		FROM (
+			SELECT *
+			FROM (`+matches+`) matches
+			ORDER BY rank DESC, title, guid
+			`+page+`
+		) hits, search s
+		ORDER BY hits.rank DESC, hits.title, hits.guid
+	`, query.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			Type      sql.NullString
+			Guid      sql.NullString
+			Title     sql.NullString
+			Code      sql.NullString
+			CountryId sql.NullString
+			Rank      sql.NullFloat64
+			Highlight sql.NullString
+		)
+
+		err = rows.Scan(
+			&Type,
+			&Guid,
+			&Title,
+			&Code,
+			&CountryId,
+			&Rank,
+			&Highlight,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Hits = append(resp.Hits, models.SearchHit{
+			Type:      Type.String,
+			Guid:      Guid.String,
+			Title:     Title.String,
+			Code:      Code.String,
+			CountryId: CountryId.String,
+			Rank:      Rank.Float64,
+			Highlight: highlight(Highlight.String),
+		})
+	}
+
+	return &resp, rows.Err()
+}
+
+// highlight escapes the headline for HTML and puts the matched words in <b>
+// tags.
+func highlight(headline string) string {
+	return strings.NewReplacer(searchStartSel, "<b>", searchStopSel, "</b>").Replace(html.EscapeString(headline))
+}
+
+// searchTerms turns the words of a query into a tsquery matching all of
+// them, the last one as a prefix, so results come up while typing. Anything
+// but letters and digits separates words, which also keeps the tsquery
+// operators out.
+func searchTerms(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	words[len(words)-1] += ":*"
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"ret/api/models"
+	"ret/pkg/dataset"
+	"ret/pkg/helpers"
+)
+
+var timezoneImporter = importer{
+	Slug:      models.ImportEntityTimezone,
+	Model:     models.Timezone{},
+	Required:  []string{"guid", "title"},
+	FileNames: []string{"timezone", "timezones"},
+	Table: importTable{
+		Name:    "timezone",
+		Columns: []string{"guid", "title", "created_at", "updated_at"},
+	},
+	Values: timezoneImportValues,
+}
+
+func timezoneImportValues(record dataset.Record) ([]interface{}, error) {
+	var timezone models.Timezone
+	if err := dataset.Bind(record, &timezone); err != nil {
+		return nil, err
+	}
+
+	if err := validateTimezone(timezone); err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		timezone.Guid, timezone.Title,
+		helpers.NewNullString(timezone.CreatedAt), helpers.NewNullString(timezone.UpdatedAt),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"database/sql"
+	"ret/api/models"
+	"ret/pkg/helpers"
+
+	"github.com/google/uuid"
+)
+
+type UploadRepo struct {
+	db *sql.DB
+}
+
+func NewUploadRepo(db *sql.DB) *UploadRepo {
+	return &UploadRepo{
+		db: db,
+	}
+}
+
+func (r *UploadRepo) Create(req models.CreateUpload) (*models.Upload, error) {
+	var id string
+
+	err := r.db.QueryRow(`
+		INSERT INTO uploads(
+			guid,
+			entity,
+			file_name,
+			format,
+			size,
+			uploaded_by,
+			updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NOW()) RETURNING guid`,
+		uuid.New().String(),
+		req.Entity,
+		req.FileName,
+		req.Format,
+		req.Size,
+		helpers.NewNullString(req.UploadedBy),
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetById(models.UploadPrimaryKey{Id: id})
+}
+
+func (r *UploadRepo) GetById(req models.UploadPrimaryKey) (*models.Upload, error) {
+	var (
+		Guid       sql.NullString
+		Entity     sql.NullString
+		FileName   sql.NullString
+		Format     sql.NullString
+		Size       sql.NullInt64
+		Checksum   sql.NullString
+		UploadedBy sql.NullString
+		CreatedAt  sql.NullString
+		UpdatedAt  sql.NullString
+	)
+
+	err := r.db.QueryRow(`
+		SELECT
+			guid,
+			entity,
+			file_name,
+			format,
+			size,
+			checksum,
+			uploaded_by,
+			created_at,
+			updated_at
+		FROM uploads
+		WHERE guid = $1
+	`, req.Id).Scan(
+		&Guid,
+		&Entity,
+		&FileName,
+		&Format,
+		&Size,
+		&Checksum,
+		&UploadedBy,
+		&CreatedAt,
+		&UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Upload{
+		Guid:       Guid.String,
+		Entity:     Entity.String,
+		FileName:   FileName.String,
+		Format:     Format.String,
+		Size:       Size.Int64,
+		Checksum:   Checksum.String,
+		UploadedBy: UploadedBy.String,
+		CreatedAt:  CreatedAt.String,
+		UpdatedAt:  UpdatedAt.String,
+	}, nil
+}
+
+// Update records the checksum of a finalized upload.
+func (r *UploadRepo) Update(req models.UpdateUpload) (*models.Upload, error) {
+	_, err := r.db.Exec(`
+		UPDATE uploads
+		SET
+			checksum = $2,
+			updated_at = NOW()
+		WHERE guid = $1
+	`, req.Guid, req.Checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetById(models.UploadPrimaryKey{Id: req.Guid})
+}
+
+func (r *UploadRepo) Delete(req models.UploadPrimaryKey) error {
+	_, err := r.db.Exec(`DELETE FROM uploads WHERE guid = $1`, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
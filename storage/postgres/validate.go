@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"ret/api/models"
+	"ret/pkg/helpers"
+	"strconv"
+)
+
+// fieldError rejects an import row because of a single field.
+type fieldError struct {
+	field  string
+	reason string
+}
+
+func (e *fieldError) Error() string {
+	return e.field + ": " + e.reason
+}
+
+func validateCountry(country models.Country) error {
+	if !helpers.IsValidUUID(country.Guid) {
+		return &fieldError{"guid", "is not uuid"}
+	}
+	if country.Title == "" {
+		return &fieldError{"title", "is required"}
+	}
+	if !helpers.IsValidCountryCode(country.Code) {
+		return &fieldError{"code", "is not an ISO 3166-1 alpha-2 code"}
+	}
+	if !helpers.IsValidContinent(country.Continent) {
+		return &fieldError{"continent", "is not a continent code"}
+	}
+
+	return validateTimestamps(country.CreatedAt, country.UpdatedAt)
+}
+
+func validateCity(city models.City) error {
+	if !helpers.IsValidUUID(city.Guid) {
+		return &fieldError{"guid", "is not uuid"}
+	}
+	if city.Title == "" {
+		return &fieldError{"title", "is required"}
+	}
+	if city.Latitude != "" {
+		latitude, err := strconv.ParseFloat(city.Latitude, 64)
+		if err != nil || !helpers.IsValidLatitude(latitude) {
+			return &fieldError{"latitude", "must be a number between -90 and 90"}
+		}
+	}
+	if city.Longitude != "" {
+		longitude, err := strconv.ParseFloat(city.Longitude, 64)
+		if err != nil || !helpers.IsValidLongitude(longitude) {
+			return &fieldError{"longitude", "must be a number between -180 and 180"}
+		}
+	}
+
+	return validateTimestamps(city.CreatedAt, city.UpdatedAt)
+}
+
+func validateAirport(airport models.Airport) error {
+	if !helpers.IsValidUUID(airport.Guid) {
+		return &fieldError{"guid", "is not uuid"}
+	}
+	if airport.Title == "" {
+		return &fieldError{"title", "is required"}
+	}
+	if !helpers.IsValidLatitude(airport.Latitude) {
+		return &fieldError{"latitude", "must be between -90 and 90"}
+	}
+	if !helpers.IsValidLongitude(airport.Longitude) {
+		return &fieldError{"longitude", "must be between -180 and 180"}
+	}
+
+	return validateTimestamps(airport.CreatedAt, airport.UpdatedAt)
+}
+
+func validateTimezone(timezone models.Timezone) error {
+	if !helpers.IsValidUUID(timezone.Guid) {
+		return &fieldError{"guid", "is not uuid"}
+	}
+	if !helpers.IsValidTimezone(timezone.Title) {
+		return &fieldError{"title", "is not an IANA time zone"}
+	}
+
+	return validateTimestamps(timezone.CreatedAt, timezone.UpdatedAt)
+}
+
+// validateTimestamps checks the optional created_at and updated_at of a row.
+func validateTimestamps(createdAt, updatedAt string) error {
+	if createdAt != "" && !helpers.IsValidTimestamp(createdAt) {
+		return &fieldError{"created_at", "is not a timestamp"}
+	}
+	if updatedAt != "" && !helpers.IsValidTimestamp(updatedAt) {
+		return &fieldError{"updated_at", "is not a timestamp"}
+	}
+
+	return nil
+}
+
+// rowExists reports whether table has a row with the given guid.
+func rowExists(ctx context.Context, tx *sql.Tx, table, guid string) (bool, error) {
+	var count int
+	err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table+` WHERE guid::text = $1`, guid).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -1,13 +1,34 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"ret/api/models"
+	"ret/pkg/dataset"
 )
 
+// ErrRollbackConflict means an import batch cannot be rolled back, because
+// the job did not complete or its rows were changed since, by a later
+// import or by hand.
+var ErrRollbackConflict = errors.New("import cannot be rolled back")
+
+// ErrInvalidListQuery means the filters or the sort of a list request are
+// not valid, like an unknown sort field.
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// ErrImportCancelled means the job of an import was cancelled before the
+// import could commit, so nothing was written.
+var ErrImportCancelled = errors.New("import job was cancelled")
+
 type StorageI interface {
 	City() CityRepoI
 	Airport() AirportRepoI
 	Country() CountryRepoI
+	ImportJob() ImportJobRepoI
+	Import() ImportRepoI
+	Upload() UploadRepoI
+	MappingProfile() MappingProfileRepoI
+	Search() SearchRepoI
 }
 
 type CountryRepoI interface {
@@ -16,7 +37,6 @@ type CountryRepoI interface {
 	GetById(req models.CountryPrimaryKey) (*models.Country, error)
 	GetList(req models.GetListCountryRequest) (*models.GetListCountryResponse, error)
 	Delete(req models.CountryPrimaryKey) error
-	ImportFromFileCountry(filePath string) error
 }
 
 type CityRepoI interface {
@@ -25,7 +45,6 @@ type CityRepoI interface {
 	GetById(req models.CityPrimaryKey) (*models.City, error)
 	GetList(req models.GetListCityRequest) (*models.GetListCityResponse, error)
 	Delete(req models.CityPrimaryKey) error
-	ImportFromFile(filePath string) error
 }
 
 type AirportRepoI interface {
@@ -34,6 +53,46 @@ type AirportRepoI interface {
 	GetById(req models.AirportPrimaryKey) (*models.Airport, error)
 	GetList(req models.GetListAirportRequest) (*models.GetListAirportResponse, error)
 	Delete(req models.AirportPrimaryKey) error
-	ImportFromFileAirport(filePath string) error
+	GetImage(req models.AirportPrimaryKey) (*models.AirportImage, error)
+	UpdateImage(req models.UpdateAirportImage) (*models.Airport, error)
+}
+
+type ImportJobRepoI interface {
+	Create(req models.CreateImportJob) (*models.ImportJob, error)
+	GetById(req models.ImportJobPrimaryKey) (*models.ImportJob, error)
+	GetList(req models.GetListImportJobRequest) (*models.GetListImportJobResponse, error)
+	GetDuplicate(req models.CreateImportJob) (*models.ImportJob, error)
+	Update(req models.UpdateImportJob) (*models.ImportJob, error)
+	Requeue() ([]string, error)
+	Delete(req models.ImportJobPrimaryKey) error
+	Rollback(ctx context.Context, req models.ImportJobPrimaryKey) (*models.ImportRollback, error)
+	CreateReport(req models.CreateImportReport) error
+	GetReport(req models.ImportJobPrimaryKey) (*models.ImportReport, error)
+}
+
+type UploadRepoI interface {
+	Create(req models.CreateUpload) (*models.Upload, error)
+	GetById(req models.UploadPrimaryKey) (*models.Upload, error)
+	Update(req models.UpdateUpload) (*models.Upload, error)
+	Delete(req models.UploadPrimaryKey) error
+}
+
+type MappingProfileRepoI interface {
+	Create(req models.CreateMappingProfile) (*models.MappingProfile, error)
+	Update(req models.UpdateMappingProfile) (*models.MappingProfile, error)
+	GetById(req models.MappingProfilePrimaryKey) (*models.MappingProfile, error)
+	GetList(req models.GetListMappingProfileRequest) (*models.GetListMappingProfileResponse, error)
+	Delete(req models.MappingProfilePrimaryKey) error
 }
 
+type ImportRepoI interface {
+	Schemas() []models.ImportSchema
+	Schema(slug string) (*models.ImportSchema, bool)
+	ImportFile(ctx context.Context, slug string, req models.ImportRequest) (*models.ImportResult, error)
+	ImportBundle(ctx context.Context, req models.ImportRequest, files []models.ImportBundleFile) (*models.ImportResult, error)
+	Export(ctx context.Context, req models.ExportRequest, each func(dataset.Record) error) error
+}
+
+type SearchRepoI interface {
+	Search(req models.SearchRequest) (*models.SearchResponse, error)
+}
@@ -0,0 +1,464 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"ret/api/models"
+	"ret/config"
+	"ret/pkg/dataset"
+	"ret/pkg/filestore"
+	"ret/storage"
+	"strings"
+	"time"
+)
+
+// Folders of the inbox. Files being imported wait in processing/ so they
+// are not picked up twice; the finished ones end up in processed/ or
+// failed/ next to a <name>.report.json sidecar.
+const (
+	inboxProcessing = "processing"
+	inboxProcessed  = "processed"
+	inboxFailed     = "failed"
+)
+
+// inboxStamp prefixes the names of the files in processing/, so a file
+// dropped again while the first one is imported does not replace it.
+const inboxStamp = "20060102T150405.000000000"
+
+// inboxUploader is the uploaded_by of the jobs the inbox creates.
+const inboxUploader = "inbox"
+
+// Inbox imports the dataset files dropped into a directory. It polls the
+// directory, so it also works on network shares that do not deliver file
+// system events, and only takes a file once its size and modification time
+// stopped changing between two polls, so half-synced files are left alone.
+type Inbox struct {
+	cfg   *config.Config
+	strg  storage.StorageI
+	pool  *Pool
+	files *filestore.Store
+	dir   string
+
+	// seen is the size and modification time of the waiting files at the
+	// last poll, pending the files of each running job: a file with the
+	// same content as one being imported gets its job too.
+	seen    map[string]inboxStat
+	pending map[string][]inboxFile
+}
+
+type inboxStat struct {
+	size    int64
+	modTime time.Time
+}
+
+// inboxFile is a file taken from the inbox: its name there and the name it
+// has in processing/.
+type inboxFile struct {
+	name   string
+	staged string
+}
+
+// inboxReport is the sidecar written next to a finished file.
+type inboxReport struct {
+	File   string               `json:"file"`
+	Entity string               `json:"entity,omitempty"`
+	Error  string               `json:"error,omitempty"`
+	Report *models.ImportReport `json:"report,omitempty"`
+}
+
+func NewInbox(cfg *config.Config, strg storage.StorageI, pool *Pool, files *filestore.Store) *Inbox {
+	return &Inbox{
+		cfg:     cfg,
+		strg:    strg,
+		pool:    pool,
+		files:   files,
+		dir:     cfg.InboxDir,
+		seen:    make(map[string]inboxStat),
+		pending: make(map[string][]inboxFile),
+	}
+}
+
+// Run watches the inbox until ctx is done. Files left in processing/ by a
+// previous run are put back first, under their own name unless a newer
+// file took it; importing them again finds the job that already ran.
+func (in *Inbox) Run(ctx context.Context) error {
+	if err := in.checkOptions(); err != nil {
+		return err
+	}
+
+	for _, folder := range []string{inboxProcessing, inboxProcessed, inboxFailed} {
+		if err := os.MkdirAll(filepath.Join(in.dir, folder), 0755); err != nil {
+			return err
+		}
+	}
+
+	leftovers, err := os.ReadDir(filepath.Join(in.dir, inboxProcessing))
+	if err != nil {
+		return err
+	}
+	for _, entry := range leftovers {
+		name := originalName(entry.Name())
+		if _, err := os.Stat(filepath.Join(in.dir, name)); err == nil {
+			name = entry.Name()
+		}
+
+		err := os.Rename(filepath.Join(in.dir, inboxProcessing, entry.Name()), filepath.Join(in.dir, name))
+		if err != nil {
+			return err
+		}
+	}
+
+	interval := in.cfg.InboxPollInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		in.finish()
+		in.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (in *Inbox) checkOptions() error {
+	switch in.cfg.InboxMode {
+	case models.ImportModeStrict, models.ImportModePartial:
+	default:
+		return fmt.Errorf("unknown INBOX_MODE %q", in.cfg.InboxMode)
+	}
+
+	switch in.cfg.InboxStrategy {
+	case models.ImportStrategyInsertOnly, models.ImportStrategyUpsert, models.ImportStrategySkipExisting, models.ImportStrategyReplaceAll:
+	default:
+		return fmt.Errorf("unknown INBOX_STRATEGY %q", in.cfg.InboxStrategy)
+	}
+
+	switch in.cfg.InboxLoader {
+	case models.ImportLoaderRow, models.ImportLoaderCopy:
+	default:
+		return fmt.Errorf("unknown INBOX_LOADER %q", in.cfg.InboxLoader)
+	}
+
+	switch in.cfg.InboxOnMissing {
+	case "", models.ImportOnMissingReject, models.ImportOnMissingNull, models.ImportOnMissingStub:
+	default:
+		return fmt.Errorf("unknown INBOX_ON_MISSING %q", in.cfg.InboxOnMissing)
+	}
+
+	if profile := in.cfg.InboxMappingProfile; profile != "" {
+		if _, err := in.strg.MappingProfile().GetById(models.MappingProfilePrimaryKey{Id: profile}); err != nil {
+			return fmt.Errorf("INBOX_MAPPING_PROFILE %q: %w", profile, err)
+		}
+	}
+
+	return nil
+}
+
+// scan starts importing the files that are ready, as long as the pool has
+// room for them.
+func (in *Inbox) scan(ctx context.Context) {
+	entries, err := os.ReadDir(in.dir)
+	if err != nil {
+		log.Println(config.Error, "inbox:", err)
+		return
+	}
+
+	seen := make(map[string]inboxStat, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || isPartialFile(name) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		stat := inboxStat{size: info.Size(), modTime: info.ModTime()}
+		seen[name] = stat
+
+		if last, ok := in.seen[name]; !ok || last != stat {
+			continue
+		}
+		if len(in.pending) >= in.pool.size {
+			continue
+		}
+
+		delete(seen, name)
+		in.start(ctx, name)
+	}
+
+	in.seen = seen
+}
+
+// start moves the file name to processing/ and queues its import job.
+func (in *Inbox) start(ctx context.Context, name string) {
+	file := inboxFile{name: name, staged: time.Now().UTC().Format(inboxStamp) + "-" + name}
+	path := filepath.Join(in.dir, inboxProcessing, file.staged)
+	if err := os.Rename(filepath.Join(in.dir, name), path); err != nil {
+		log.Println(config.Error, "inbox:", name, err)
+		return
+	}
+
+	job, err := in.enqueue(ctx, name, path)
+	if err != nil {
+		in.move(file, inboxFailed, inboxReport{File: name, Error: err.Error()})
+		return
+	}
+
+	log.Println(config.Info, "inbox:", name, "is imported by job", job.Guid)
+	in.pending[job.Guid] = append(in.pending[job.Guid], file)
+}
+
+// enqueue runs the file at path through the same steps as an upload: the
+// entity and format are recognised, the file is stored and a job is queued,
+// unless the same file was already imported with the same options.
+func (in *Inbox) enqueue(ctx context.Context, name, path string) (*models.ImportJob, error) {
+	head, err := dataset.HeadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := dataset.Detect(name, head)
+	if err != nil {
+		return nil, err
+	}
+
+	entity, err := in.entityOf(name, path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stored, err := in.files.Save(ctx, file, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	req := models.CreateImportJob{
+		Entity:     entity,
+		FileName:   name,
+		FilePath:   stored.Key,
+		Checksum:   stored.Checksum,
+		UploadedBy: inboxUploader,
+		Format:     format,
+		Mode:       in.cfg.InboxMode,
+		Strategy:   in.cfg.InboxStrategy,
+		Loader:     in.cfg.InboxLoader,
+		OnMissing:  in.cfg.InboxOnMissing,
+	}
+
+	// A profile made for one table is only applied to the files of it.
+	if profile := in.cfg.InboxMappingProfile; profile != "" {
+		mapping, err := in.strg.MappingProfile().GetById(models.MappingProfilePrimaryKey{Id: profile})
+		if err != nil {
+			return nil, fmt.Errorf("mapping profile %s: %w", profile, err)
+		}
+		if mapping.Entity == "" || mapping.Entity == entity {
+			req.MappingProfileId = profile
+		}
+	}
+
+	job, err := in.strg.ImportJob().GetDuplicate(req)
+	if err == nil {
+		return job, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	job, err = in.strg.ImportJob().Create(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := in.pool.Submit(job.Guid); err != nil {
+		if err := in.strg.ImportJob().Delete(models.ImportJobPrimaryKey{Id: job.Guid}); err != nil {
+			log.Println(config.Error, "import job", job.Guid, "is not deleted:", err)
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// entityOf recognises the entity of a file by its name, the way archive
+// files without a manifest are, or else by the columns of its first row.
+func (in *Inbox) entityOf(name, path, format string) (string, error) {
+	if format == dataset.FormatZip {
+		return models.ImportEntityArchive, nil
+	}
+
+	schemas := in.strg.Import().Schemas()
+
+	base := strings.ToLower(strings.TrimSuffix(name, ".gz"))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	words := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+	for _, schema := range schemas {
+		for _, word := range words {
+			if word == schema.Slug || word == schema.Table || contains(schema.FileNames, word) {
+				return schema.Slug, nil
+			}
+		}
+	}
+
+	// A workbook not named after a table is read like an archive, a sheet
+	// per table.
+	if format == dataset.FormatXLSX {
+		return models.ImportEntityArchive, nil
+	}
+
+	reader, err := dataset.Open(path, format, dataset.Options{Aliases: in.cfg.ImportColumnAliases})
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	record, err := reader.Read()
+	if err != nil {
+		return "", fmt.Errorf("cannot read the first row: %w", err)
+	}
+
+	// The best schema has all its required fields and the most fields in
+	// common with the row; a tie is not guessed.
+	var best string
+	bestScore, tie := 0, false
+	for _, schema := range schemas {
+		score := 0
+		for _, field := range schema.Fields {
+			if _, ok := record[field.Name]; ok {
+				score++
+			} else if field.Required {
+				score = -1
+				break
+			}
+		}
+
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = schema.Slug, score, false
+		case score == bestScore && score > 0:
+			tie = true
+		}
+	}
+
+	if best == "" || tie {
+		return "", errors.New("cannot tell the table from the file name or its columns")
+	}
+
+	return best, nil
+}
+
+// finish moves the files of the jobs that ended out of processing/.
+func (in *Inbox) finish() {
+	for id, files := range in.pending {
+		report, err := in.strg.ImportJob().GetReport(models.ImportJobPrimaryKey{Id: id})
+		if err != nil {
+			log.Println(config.Error, "inbox: job", id, err)
+			continue
+		}
+
+		folder := inboxProcessed
+		switch report.Job.Status {
+		case models.ImportJobQueued, models.ImportJobRunning:
+			continue
+		case models.ImportJobFailed, models.ImportJobCancelled:
+			folder = inboxFailed
+		}
+
+		for _, file := range files {
+			in.move(file, folder, inboxReport{
+				File:   file.name,
+				Entity: report.Job.Entity,
+				Error:  report.Job.Error,
+				Report: report,
+			})
+		}
+		delete(in.pending, id)
+	}
+}
+
+// move takes file out of processing/ into folder under its own name and
+// writes its sidecar report. A name already taken there gets the prefix of
+// processing/.
+func (in *Inbox) move(file inboxFile, folder string, report inboxReport) {
+	name := file.name
+	target := filepath.Join(in.dir, folder, name)
+	if _, err := os.Stat(target); err == nil {
+		target = filepath.Join(in.dir, folder, file.staged)
+	}
+
+	if err := os.Rename(filepath.Join(in.dir, inboxProcessing, file.staged), target); err != nil {
+		log.Println(config.Error, "inbox:", name, err)
+		return
+	}
+
+	body, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(target+".report.json", body, 0644)
+	}
+	if err != nil {
+		log.Println(config.Error, "inbox:", name, "report:", err)
+	}
+
+	log.Println(config.Info, "inbox:", name, "moved to", folder)
+}
+
+// originalName is the name a file in processing/ had in the inbox.
+func originalName(staged string) string {
+	if len(staged) <= len(inboxStamp)+1 || staged[len(inboxStamp)] != '-' {
+		return staged
+	}
+	if _, err := time.Parse(inboxStamp, staged[:len(inboxStamp)]); err != nil {
+		return staged
+	}
+
+	return staged[len(inboxStamp)+1:]
+}
+
+// isPartialFile skips hidden files and the temporary files sync tools write
+// before renaming them into place.
+func isPartialFile(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~") {
+		return true
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".tmp", ".part", ".partial", ".crdownload", ".filepart":
+		return true
+	}
+
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
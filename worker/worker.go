@@ -0,0 +1,333 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"ret/api/models"
+	"ret/config"
+	"ret/pkg/dataset"
+	"ret/pkg/filestore"
+	"ret/storage"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressInterval limits how often a running job writes its counters
+// back to import_jobs.
+const progressInterval = time.Second
+
+var ErrQueueFull = errors.New("import queue is full")
+
+// Pool runs import jobs in the background with a fixed number of workers.
+type Pool struct {
+	cfg   *config.Config
+	strg  storage.StorageI
+	files *filestore.Store
+	size  int
+	queue chan string
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func NewPool(cfg *config.Config, strg storage.StorageI, files *filestore.Store) *Pool {
+	size := cfg.ImportWorkerCount
+	if size <= 0 {
+		size = 1
+	}
+
+	return &Pool{
+		cfg:     cfg,
+		strg:    strg,
+		files:   files,
+		size:    size,
+		queue:   make(chan string, cfg.ImportQueueSize),
+		running: make(map[string]context.CancelFunc),
+	}
+}
+
+// Start launches the workers. They stop once ctx is done. The jobs queued
+// or left running when the pool last stopped are run again first.
+func (p *Pool) Start(ctx context.Context) {
+	for i := 0; i < p.size; i++ {
+		go p.work(ctx)
+	}
+
+	ids, err := p.strg.ImportJob().Requeue()
+	if err != nil {
+		log.Println(config.Error, "import jobs are not requeued:", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	log.Println(config.Info, "Requeued", len(ids), "import jobs")
+
+	// There may be more of them than the queue holds, they wait for room.
+	go func() {
+		for _, id := range ids {
+			select {
+			case p.queue <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Submit queues an already created import job.
+func (p *Pool) Submit(id string) error {
+	select {
+	case p.queue <- id:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Cancel stops a job that is currently being imported. It returns false if
+// the job is not running in this pool.
+func (p *Pool) Cancel(id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cancel, ok := p.running[id]
+	if ok {
+		cancel()
+	}
+
+	return ok
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-p.queue:
+			p.run(ctx, id)
+		}
+	}
+}
+
+func (p *Pool) run(ctx context.Context, id string) {
+	// The cancel func is registered before the job is marked running, so a
+	// cancel never finds a running job the pool does not know of.
+	jobCtx, cancel := context.WithCancel(ctx)
+	p.mu.Lock()
+	p.running[id] = cancel
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.running, id)
+		p.mu.Unlock()
+		cancel()
+	}()
+
+	job, err := p.strg.ImportJob().Update(models.UpdateImportJob{Guid: id, Status: models.ImportJobRunning})
+	if err != nil {
+		log.Println(config.Error, "import job", id, "does not start:", err)
+		return
+	}
+
+	// Cancelled while waiting in the queue.
+	if job.Status != models.ImportJobRunning {
+		return
+	}
+
+	// The profile is read when the job runs, a deleted one fails the job.
+	var mapping *models.MappingRules
+	if job.MappingProfileId != "" {
+		profile, err := p.strg.MappingProfile().GetById(models.MappingProfilePrimaryKey{Id: job.MappingProfileId})
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("mapping profile %s does not exist", job.MappingProfileId)
+		}
+		if err != nil {
+			p.fail(ctx, id, err)
+			return
+		}
+		mapping = &profile.Rules
+	}
+
+	// The file of the job is a blob key, see filestore.
+	filePath, release, err := p.files.Fetch(jobCtx, job.FilePath)
+	if err != nil {
+		p.fail(ctx, id, err)
+		return
+	}
+	defer release()
+
+	var (
+		progress     models.ImportResult
+		lastProgress = time.Now()
+	)
+
+	req := models.ImportRequest{
+		FilePath:     filePath,
+		Format:       job.Format,
+		Aliases:      p.cfg.ImportColumnAliases,
+		KeepLegacyId: p.cfg.ImportKeepLegacyId,
+		Mode:         job.Mode,
+		Strategy:     job.Strategy,
+		Loader:       job.Loader,
+		Mapping:      mapping,
+		OnMissing:    job.OnMissing,
+		Sheet:        job.Sheet,
+		UnpackLimit:  p.cfg.UploadLimit(job.Entity),
+		BatchId:      job.Guid,
+		OnProgress: func(result models.ImportResult) {
+			progress = result
+			if time.Since(lastProgress) < progressInterval {
+				return
+			}
+			lastProgress = time.Now()
+
+			_, err := p.strg.ImportJob().Update(jobUpdate(id, models.ImportJobRunning, result))
+			if err != nil {
+				log.Println(config.Error, "import job", id, "progress:", err)
+			}
+		},
+	}
+
+	result, importErr := Import(jobCtx, p.strg, job.Entity, req)
+	if result != nil {
+		progress = *result
+	}
+
+	// The pool is stopping: the import was rolled back and runs again on
+	// the next Start, its errors are reported then.
+	if importErr != nil && ctx.Err() != nil {
+		p.fail(ctx, id, importErr)
+		return
+	}
+
+	if len(progress.Errors) > 0 {
+		err := p.strg.ImportJob().CreateReport(models.CreateImportReport{JobId: id, Errors: progress.Errors})
+		if err != nil {
+			log.Println(config.Error, "import job", id, "report:", err)
+		}
+	}
+
+	update := jobUpdate(id, models.ImportJobCompleted, progress)
+
+	switch {
+	case importErr != nil && (jobCtx.Err() != nil || errors.Is(importErr, storage.ErrImportCancelled)):
+		update.Status = models.ImportJobCancelled
+	case importErr != nil:
+		update.Status = models.ImportJobFailed
+		update.Error = importErr.Error()
+	}
+
+	_, err = p.strg.ImportJob().Update(update)
+	if err != nil {
+		log.Println(config.Error, "import job", id, "does not finish:", err)
+	}
+}
+
+// fail marks job id failed with err. If the pool is stopping, the job goes
+// back to the queue instead, a shutdown is not the fault of the import.
+func (p *Pool) fail(ctx context.Context, id string, err error) {
+	update := models.UpdateImportJob{Guid: id, Status: models.ImportJobFailed, Error: err.Error()}
+	if ctx.Err() != nil {
+		update = models.UpdateImportJob{Guid: id, Status: models.ImportJobQueued}
+	}
+
+	_, err = p.strg.ImportJob().Update(update)
+	if err != nil {
+		log.Println(config.Error, "import job", id, "does not finish:", err)
+	}
+}
+
+func jobUpdate(id, status string, result models.ImportResult) models.UpdateImportJob {
+	return models.UpdateImportJob{
+		Guid:          id,
+		Status:        status,
+		RowsProcessed: result.RowsProcessed,
+		RowsInserted:  result.RowsInserted,
+		RowsUpdated:   result.RowsUpdated,
+		RowsSkipped:   result.RowsSkipped,
+		RowsDeleted:   result.RowsDeleted,
+		RowsFailed:    result.RowsFailed,
+	}
+}
+
+// Import runs the importer of entity in the calling goroutine.
+func Import(ctx context.Context, strg storage.StorageI, entity string, req models.ImportRequest) (*models.ImportResult, error) {
+	if entity == models.ImportEntityArchive && req.Format == dataset.FormatXLSX {
+		return importWorkbook(ctx, strg, req)
+	}
+	if entity == models.ImportEntityArchive {
+		return importArchive(ctx, strg, req)
+	}
+
+	return strg.Import().ImportFile(ctx, entity, req)
+}
+
+// importArchive unpacks the zip of req into a temporary directory and
+// imports its files together.
+func importArchive(ctx context.Context, strg storage.StorageI, req models.ImportRequest) (*models.ImportResult, error) {
+	dir, err := os.MkdirTemp("", "import-archive-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	files, err := dataset.Extract(req.FilePath, dir, req.UnpackLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := make([]models.ImportBundleFile, 0, len(files))
+	for _, file := range files {
+		bundle = append(bundle, models.ImportBundleFile{
+			Name:     file.Name,
+			Entity:   file.Table,
+			Format:   file.Format,
+			FilePath: file.Path,
+		})
+	}
+
+	return strg.Import().ImportBundle(ctx, req, bundle)
+}
+
+// importWorkbook imports the sheets of an XLSX workbook that are named after
+// a table together, like the files of an archive. Other sheets, say notes,
+// are left alone.
+func importWorkbook(ctx context.Context, strg storage.StorageI, req models.ImportRequest) (*models.ImportResult, error) {
+	sheets, err := dataset.Sheets(req.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := strg.Import().Schemas()
+
+	var bundle []models.ImportBundleFile
+	for _, sheet := range sheets {
+		name := strings.ToLower(strings.TrimSpace(sheet))
+		for _, schema := range schemas {
+			if name == schema.Slug || name == schema.Table || contains(schema.FileNames, name) {
+				bundle = append(bundle, models.ImportBundleFile{
+					Name:     sheet,
+					Entity:   schema.Slug,
+					Format:   dataset.FormatXLSX,
+					FilePath: req.FilePath,
+					Sheet:    sheet,
+				})
+				break
+			}
+		}
+	}
+
+	if len(bundle) == 0 {
+		return nil, errors.New("workbook has no sheet named after a table, see file_names in GET /upload")
+	}
+
+	return strg.Import().ImportBundle(ctx, req, bundle)
+}